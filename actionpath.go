@@ -0,0 +1,99 @@
+package ft
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultActionPathMaxDepth is the default number of actions in the action_path attribute.
+	DefaultActionPathMaxDepth = 16
+	// DefaultActionPathMaxLength is the default length of the action_path attribute in bytes.
+	DefaultActionPathMaxLength = 256
+
+	actionPathSeparator       = ">"
+	actionPathTruncatedMarker = "..."
+)
+
+// actionPathAttrs returns the parent_action and action_path attributes of an action started within the parent span.
+func (cfg *SpanConfig) actionPathAttrs(parent *span, action string) []slog.Attr {
+	if !cfg.actionPath || parent == nil {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.String("parent_action", parent.action),
+		slog.String("action_path", actionPath(parent, action, cfg.actionPathMaxDepth, cfg.actionPathMaxLength)),
+	}
+}
+
+// actionPath joins the actions from the root span to the action, e.g. api.Handle>svc.Create>repo.Insert.
+// When the path exceeds maxDepth actions or maxLength bytes including the "...>" prefix, the outermost actions
+// are replaced with it. If there is no room for the prefix, only the action is kept and cut if needed.
+// A zero or negative limit disables it.
+func actionPath(parent *span, action string, maxDepth, maxLength int) string {
+	segments := []string{action}
+	length := len(action)
+	truncated := false
+
+	for p := parent; p != nil; p = p.parent {
+		if maxDepth > 0 && len(segments) >= maxDepth {
+			truncated = true
+			break
+		}
+
+		segments = append(segments, p.action)
+		length += len(actionPathSeparator) + len(p.action)
+	}
+
+	if maxLength > 0 {
+		for len(segments) > 1 && length+truncatedPrefixLen(truncated) > maxLength {
+			length -= len(actionPathSeparator) + len(segments[len(segments)-1])
+			segments = segments[:len(segments)-1]
+			truncated = true
+		}
+
+		if length+truncatedPrefixLen(truncated) > maxLength {
+			return truncateAction(action, maxLength)
+		}
+	}
+
+	if truncated {
+		segments = append(segments, actionPathTruncatedMarker)
+	}
+
+	slices.Reverse(segments)
+
+	return strings.Join(segments, actionPathSeparator)
+}
+
+// truncatedPrefixLen returns the length of the "...>" prefix of a truncated path.
+func truncatedPrefixLen(truncated bool) int {
+	if !truncated {
+		return 0
+	}
+
+	return len(actionPathTruncatedMarker) + len(actionPathSeparator)
+}
+
+// truncateAction cuts the action to maxLength bytes at a rune boundary and ends it with "..." if there is room for it.
+func truncateAction(action string, maxLength int) string {
+	if len(action) <= maxLength {
+		return action
+	}
+
+	n := maxLength - len(actionPathTruncatedMarker)
+	marker := actionPathTruncatedMarker
+	if n <= 0 {
+		n = maxLength
+		marker = ""
+	}
+
+	for n > 0 && !utf8.RuneStart(action[n]) {
+		n--
+	}
+
+	return action[:n] + marker
+}
@@ -0,0 +1,131 @@
+package ft
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+type (
+	spanContextKey  struct{}
+	attrsContextKey struct{}
+)
+
+// SpanFromContext returns the Span stored in the context by Start.
+// If the context carries no Span, it returns a no-op Span.
+func SpanFromContext(ctx context.Context) Span {
+	if ctx != nil {
+		if s, ok := ctx.Value(spanContextKey{}).(*span); ok {
+			return s
+		}
+	}
+
+	return noopSpan{}
+}
+
+// AddAttrs adds attributes to the Span stored in the context, see Span.AddAttrs.
+// Does nothing if the context carries no Span.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	SpanFromContext(ctx).AddAttrs(attrs...)
+}
+
+// Event records an event of the Span stored in the context, see Span.Event.
+// Does nothing if the context carries no Span.
+func Event(ctx context.Context, name string, attrs ...slog.Attr) {
+	if s, ok := SpanFromContext(ctx).(*span); ok {
+		s.event(name, callerPC(1), attrs)
+	}
+}
+
+// ContextWithAttrs returns a copy of the context with attributes that are added to every span started with it
+// or with a context derived from it. Attributes of the same key set earlier in the context are replaced.
+//
+// Context attributes come first in span records. Attributes passed via WithAttrs or AddAttrs take precedence
+// over context attributes with the same key.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+
+	existing, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+
+	return context.WithValue(ctx, attrsContextKey{}, mergeAttrs(existing, slices.Clip(attrs)))
+}
+
+// ContextWithBaggageAttrs works like ContextWithAttrs and also stores the attributes as OpenTelemetry baggage members,
+// so that they can be propagated to other processes. Values are stored as strings,
+// attributes that are not valid baggage members are only added to the context.
+// Spans of the receiving process get the baggage members as attributes when WithBaggageAttrs is enabled.
+func ContextWithBaggageAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	bag := baggage.FromContext(ctx)
+	for _, attr := range attrs {
+		member, err := baggage.NewMemberRaw(attr.Key, attr.Value.Resolve().String())
+		if err != nil {
+			continue
+		}
+
+		if b, err := bag.SetMember(member); err == nil {
+			bag = b
+		}
+	}
+
+	return ContextWithAttrs(baggage.ContextWithBaggage(ctx, bag), attrs...)
+}
+
+// contextAttrs returns the attributes stored in the context by ContextWithAttrs and,
+// if enabled, the baggage members of the context. Context attributes take precedence over baggage members.
+func (cfg *SpanConfig) contextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+
+	if !cfg.baggageAttrs {
+		return attrs
+	}
+
+	members := baggage.FromContext(ctx).Members()
+	if len(members) == 0 {
+		return attrs
+	}
+
+	baggageAttrs := make([]slog.Attr, 0, len(members))
+	for _, member := range members {
+		baggageAttrs = append(baggageAttrs, slog.String(member.Key(), member.Value()))
+	}
+	// Members are returned in random order, sort them to keep records stable.
+	slices.SortFunc(baggageAttrs, func(a, b slog.Attr) int { return strings.Compare(a.Key, b.Key) })
+
+	return mergeAttrs(baggageAttrs, attrs)
+}
+
+// mergeAttrs returns the attributes of base whose keys are not in override, followed by override.
+func mergeAttrs(base, override []slog.Attr) []slog.Attr {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := make([]slog.Attr, 0, len(base)+len(override))
+	for _, attr := range base {
+		if !slices.ContainsFunc(override, func(o slog.Attr) bool { return o.Key == attr.Key }) {
+			merged = append(merged, attr)
+		}
+	}
+
+	return append(merged, override...)
+}
+
+// noopSpan is the Span returned by SpanFromContext when the context carries no Span.
+type noopSpan struct{}
+
+func (noopSpan) End() {}
+
+func (noopSpan) AddAttrs(...slog.Attr) {}
+
+func (noopSpan) Event(string, ...slog.Attr) {}
+
+func (noopSpan) SetError(error) {}
+
+func (noopSpan) Fail(err error) error {
+	return err
+}
@@ -0,0 +1,141 @@
+package ft
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceLogFields defines the trace correlation fields added to log records.
+// A field with an empty key is omitted.
+type TraceLogFields struct {
+	// TraceIDKey is the key of the trace ID.
+	TraceIDKey string
+	// SpanIDKey is the key of the span ID.
+	SpanIDKey string
+	// TraceFlagsKey is the key of the trace flags.
+	TraceFlagsKey string
+
+	// TraceID formats the trace ID. If nil, the hex representation is used.
+	TraceID func(id trace.TraceID) slog.Value
+	// SpanID formats the span ID. If nil, the hex representation is used.
+	SpanID func(id trace.SpanID) slog.Value
+	// TraceFlags formats the trace flags. If nil, the hex representation is used.
+	TraceFlags func(flags trace.TraceFlags) slog.Value
+}
+
+// DefaultTraceLogFields returns the fields used by default: trace_id and span_id.
+// Set TraceFlagsKey to add the trace flags as well.
+func DefaultTraceLogFields() TraceLogFields {
+	return TraceLogFields{
+		TraceIDKey: "trace_id",
+		SpanIDKey:  "span_id",
+	}
+}
+
+// DatadogTraceLogFields returns the fields expected by Datadog: dd.trace_id and dd.span_id
+// as decimal strings of the lower 64 bits of the IDs.
+func DatadogTraceLogFields() TraceLogFields {
+	return TraceLogFields{
+		TraceIDKey: "dd.trace_id",
+		SpanIDKey:  "dd.span_id",
+		TraceID: func(id trace.TraceID) slog.Value {
+			return slog.StringValue(strconv.FormatUint(binary.BigEndian.Uint64(id[8:]), 10))
+		},
+		SpanID: func(id trace.SpanID) slog.Value {
+			return slog.StringValue(strconv.FormatUint(binary.BigEndian.Uint64(id[:]), 10))
+		},
+	}
+}
+
+// GCPTraceLogFields returns the fields expected by Google Cloud Logging for the given project.
+func GCPTraceLogFields(projectID string) TraceLogFields {
+	return TraceLogFields{
+		TraceIDKey:    "logging.googleapis.com/trace",
+		SpanIDKey:     "logging.googleapis.com/spanId",
+		TraceFlagsKey: "logging.googleapis.com/trace_sampled",
+		TraceID: func(id trace.TraceID) slog.Value {
+			return slog.StringValue("projects/" + projectID + "/traces/" + id.String())
+		},
+		TraceFlags: func(flags trace.TraceFlags) slog.Value {
+			return slog.BoolValue(flags.IsSampled())
+		},
+	}
+}
+
+// ECSTraceLogFields returns the fields defined by the Elastic Common Schema: trace.id and span.id.
+func ECSTraceLogFields() TraceLogFields {
+	return TraceLogFields{
+		TraceIDKey: "trace.id",
+		SpanIDKey:  "span.id",
+	}
+}
+
+// attrs returns the fields of the span context. It returns nil if the span context is not valid.
+func (f *TraceLogFields) attrs(sc trace.SpanContext) []slog.Attr {
+	if !sc.IsValid() {
+		return nil
+	}
+
+	attrs := make([]slog.Attr, 0, 3)
+
+	if f.TraceIDKey != "" {
+		value := slog.StringValue(sc.TraceID().String())
+		if f.TraceID != nil {
+			value = f.TraceID(sc.TraceID())
+		}
+		attrs = append(attrs, slog.Attr{Key: f.TraceIDKey, Value: value})
+	}
+
+	if f.SpanIDKey != "" {
+		value := slog.StringValue(sc.SpanID().String())
+		if f.SpanID != nil {
+			value = f.SpanID(sc.SpanID())
+		}
+		attrs = append(attrs, slog.Attr{Key: f.SpanIDKey, Value: value})
+	}
+
+	if f.TraceFlagsKey != "" {
+		value := slog.StringValue(sc.TraceFlags().String())
+		if f.TraceFlags != nil {
+			value = f.TraceFlags(sc.TraceFlags())
+		}
+		attrs = append(attrs, slog.Attr{Key: f.TraceFlagsKey, Value: value})
+	}
+
+	return attrs
+}
+
+// NewLogHandler returns a slog.Handler that adds the trace correlation fields of the span in the context
+// to every record and passes it to h. It can be used for log calls outside ft, e.g. slog.InfoContext(ctx, ...),
+// made with a context returned by Start.
+//
+// When the logger of a Tracer uses the handler, the fields are not added to the records of the Tracer twice.
+func NewLogHandler(h slog.Handler, fields TraceLogFields) slog.Handler {
+	return &logHandler{handler: h, fields: fields}
+}
+
+type logHandler struct {
+	handler slog.Handler
+	fields  TraceLogFields
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(h.fields.attrs(trace.SpanContextFromContext(ctx))...)
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{handler: h.handler.WithAttrs(attrs), fields: h.fields}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{handler: h.handler.WithGroup(name), fields: h.fields}
+}
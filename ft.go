@@ -2,17 +2,22 @@ package ft
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"sync"
 
-	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/jonboulle/clockwork"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/metric"
-	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
 
 	"log/slog"
 	"time"
@@ -24,16 +29,91 @@ const (
 	DurationMetricUnitSecond = "s"
 	// DurationMetricUnitMillisecond represents milliseconds as the unit for duration metrics.
 	DurationMetricUnitMillisecond = "ms"
-)
 
-var int64Counters = xsync.NewMapOf[string, metric.Int64Counter]()
-var durationHistograms = xsync.NewMapOf[string, metric.Float64Histogram]()
+	outcomeAttrKey = "outcome"
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+
+	slowAttrKey = "slow"
+)
 
+// SpanConfig holds the settings used to start and end a span.
+// A Tracer keeps one as its defaults, and every call to Start applies its options on top of a copy.
 type SpanConfig struct {
 	err             *error
 	additionalAttrs []slog.Attr
+
+	logger             *slog.Logger
+	clock              clockwork.Clock
+	startTime          time.Time
+	tracingEnabled     bool
+	spanKind           trace.SpanKind
+	metricsEnabled     bool
+	appendOtelAttrs    bool
+	durationMetricUnit string
+	logLevelOnSuccess  slog.Level
+	logLevelOnFailure  slog.Level
+	logLevelOnEvent    slog.Level
+	logLevelOnSlow     slog.Level
+	logLevelOnStart    slog.Leveler
+	startLogEnabled    bool
+	slowThreshold      time.Duration
+	tracerProvider     trace.TracerProvider
+	meterProvider      metric.MeterProvider
+	loggerProvider     log.LoggerProvider
+	logOutput          LogOutput
+	panicMode          PanicMode
+	baggageAttrs       bool
+
+	actionPath          bool
+	actionPathMaxDepth  int
+	actionPathMaxLength int
+
+	traceLogFields               TraceLogFields
+	logSampler                   LogSampler
+	traceLogMode                 TraceLogMode
+	logLevelOnUnsampled          slog.Level
+	logSuppressionReportInterval time.Duration
+
+	metricAttrKeys             []string
+	metricAttrCardinalityLimit int
+	metricNaming               MetricNaming
+	defaultDurationBuckets     []float64
+	actionDurationBuckets      []actionBuckets
 }
 
+func defaultSpanConfig() SpanConfig {
+	return SpanConfig{
+		logger:             slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		clock:              clockwork.NewRealClock(),
+		spanKind:           trace.SpanKindInternal,
+		durationMetricUnit: DurationMetricUnitMillisecond,
+		logLevelOnSuccess:  slog.LevelInfo,
+		logLevelOnFailure:  slog.LevelError,
+		logLevelOnEvent:    slog.LevelInfo,
+		logLevelOnSlow:     slog.LevelWarn,
+		startLogEnabled:    true,
+
+		metricAttrCardinalityLimit:   DefaultMetricAttrCardinalityLimit,
+		actionPathMaxDepth:           DefaultActionPathMaxDepth,
+		actionPathMaxLength:          DefaultActionPathMaxLength,
+		logSuppressionReportInterval: DefaultLogSuppressionReportInterval,
+		logLevelOnUnsampled:          slog.LevelDebug,
+		traceLogFields:               DefaultTraceLogFields(),
+	}
+}
+
+// clone returns a copy of the config whose slices can be appended to without affecting the original.
+func (cfg *SpanConfig) clone() SpanConfig {
+	c := *cfg
+	c.additionalAttrs = slices.Clip(c.additionalAttrs)
+	c.metricAttrKeys = slices.Clip(c.metricAttrKeys)
+	c.actionDurationBuckets = slices.Clip(c.actionDurationBuckets)
+
+	return c
+}
+
+// Option configures a Tracer when passed to New, or a single span when passed to Start.
 type Option func(cfg *SpanConfig)
 
 func WithErr(err *error) Option {
@@ -48,95 +128,349 @@ func WithAttrs(attrs ...slog.Attr) Option {
 	}
 }
 
-// Span represents a traced and logged operation that can be ended.
-type Span interface {
-	End()
-	AddAttrs(attrs ...slog.Attr)
+// WithLogger sets the logger used to write span records. A nil logger is ignored.
+func WithLogger(l *slog.Logger) Option {
+	return func(cfg *SpanConfig) {
+		if l != nil {
+			cfg.logger = l
+		}
+	}
 }
 
-type span struct {
-	ctx             context.Context
-	start           time.Time
-	action          string
-	traceSpan       trace.Span
-	err             *error
-	additionalAttrs []slog.Attr
-	mu              sync.RWMutex
+// WithClock sets the clock used to measure span durations. A nil clock is ignored.
+func WithClock(c clockwork.Clock) Option {
+	return func(cfg *SpanConfig) {
+		if c != nil {
+			cfg.clock = c
+		}
+	}
 }
 
-// Start begins a new traced and logged span for the given action.
-// It returns an updated context and a Span that should be ended when the operation completes.
-func Start(ctx context.Context, action string, opts ...Option) (context.Context, Span) {
-	now := (*globalClock.Load()).Now()
+// WithStartTime sets the start time of a span instead of the current time of the clock.
+// It is meant for operations that are only recorded after they completed, see Tracer.Now.
+func WithStartTime(t time.Time) Option {
+	return func(cfg *SpanConfig) {
+		cfg.startTime = t
+	}
+}
 
-	cfg := &SpanConfig{}
+// WithTracingEnabled enables or disables OpenTelemetry tracing.
+func WithTracingEnabled(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.tracingEnabled = v
+	}
+}
 
-	for _, opt := range opts {
-		opt(cfg)
+// WithSpanKind sets the kind of the OpenTelemetry span. Defaults to trace.SpanKindInternal.
+func WithSpanKind(kind trace.SpanKind) Option {
+	return func(cfg *SpanConfig) {
+		cfg.spanKind = kind
 	}
+}
 
-	if ctx == nil {
-		ctx = context.Background()
+// WithMetricsEnabled enables or disables OpenTelemetry metrics.
+func WithMetricsEnabled(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.metricsEnabled = v
 	}
-	var otelSpan trace.Span
+}
 
-	if globalTracingEnabled.Load() {
-		ctx, otelSpan = otel.Tracer(
-			instrumentationName,
-			trace.WithSchemaURL(semconv.SchemaURL),
-		).Start(
-			ctx,
-			action,
-			trace.WithSpanKind(trace.SpanKindInternal),
-			trace.WithAttributes(
-				attribute.String("action", action),
-			),
-			trace.WithTimestamp(now),
-		)
+// WithAppendOtelAttrs enables or disables copying span attributes to the OpenTelemetry span.
+func WithAppendOtelAttrs(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.appendOtelAttrs = v
 	}
+}
 
-	if otelSpan != nil && otelSpan.IsRecording() && globalAppendOtelAttrs.Load() && len(cfg.additionalAttrs) > 0 {
-		otelAttrs := make([]attribute.KeyValue, 0, len(cfg.additionalAttrs))
-		for _, attr := range cfg.additionalAttrs {
-			otelAttrs = append(otelAttrs, mapSlogAttrToOtel(attr))
+// WithDurationMetricUnit sets the unit of the duration metric and log attribute.
+// Accepts DurationMetricUnitMillisecond or DurationMetricUnitSecond, any other value falls back to milliseconds.
+func WithDurationMetricUnit(unit string) Option {
+	return func(cfg *SpanConfig) {
+		switch unit {
+		case DurationMetricUnitMillisecond, DurationMetricUnitSecond:
+			cfg.durationMetricUnit = unit
+		default:
+			cfg.durationMetricUnit = DurationMetricUnitMillisecond
 		}
-		otelSpan.SetAttributes(otelAttrs...)
 	}
+}
+
+// WithLogLevelOnSuccess sets the level of records logged when an action ends without an error.
+// It is also the level of "action started" records unless WithLogLevelOnStart is set.
+func WithLogLevelOnSuccess(level slog.Level) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnSuccess = level
+	}
+}
 
-	if globalMetricsEnabled.Load() {
-		metricName := action + "_counter"
-		counter, ok := int64Counters.Load(metricName)
+// WithLogLevelOnFailure sets the level of records logged when an action ends with an error.
+func WithLogLevelOnFailure(level slog.Level) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnFailure = level
+	}
+}
 
-		if !ok {
-			var err error
-			counter, err = otel.GetMeterProvider().Meter(instrumentationName).Int64Counter(metricName)
-			if err == nil {
-				int64Counters.Store(metricName, counter)
-				ok = true
-			}
-		}
+// WithLogLevelOnEvent sets the level of records logged by span.Event.
+func WithLogLevelOnEvent(level slog.Level) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnEvent = level
+	}
+}
 
-		if ok {
-			counter.Add(ctx, 1)
-		}
+// WithLogLevelOnStart sets the level of "action started" records. A *slog.LevelVar can be passed
+// to change the level at runtime. If not set or nil, the success level is used.
+func WithLogLevelOnStart(level slog.Leveler) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnStart = level
+	}
+}
+
+// WithStartLogEnabled enables or disables "action started" records. They are enabled by default.
+func WithStartLogEnabled(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.startLogEnabled = v
+	}
+}
+
+// WithoutStartLog disables the "action started" record, e.g. for a single hot call.
+func WithoutStartLog() Option {
+	return WithStartLogEnabled(false)
+}
+
+// WithLogLevelOnSlow sets the level of records logged when an action ends without an error
+// but takes longer than the slow threshold. Defaults to slog.LevelWarn.
+func WithLogLevelOnSlow(level slog.Level) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnSlow = level
+	}
+}
+
+// WithSlowThreshold sets the duration after which a successful action is considered slow.
+// Slow actions are logged at the slow level with slow=true, get the slow attribute on the OpenTelemetry span
+// and increment the slow call counter. Zero or a negative threshold disables the detection.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(cfg *SpanConfig) {
+		cfg.slowThreshold = d
+	}
+}
+
+// WithTracerProvider sets the TracerProvider used to create OpenTelemetry spans.
+// If not set or nil, the global TracerProvider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *SpanConfig) {
+		cfg.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the MeterProvider used to create metric instruments.
+// If not set or nil, the global MeterProvider is used.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(cfg *SpanConfig) {
+		cfg.meterProvider = mp
+	}
+}
+
+// WithLoggerProvider sets the LoggerProvider used to emit OpenTelemetry log records, see WithLogOutput.
+// If not set or nil, the global LoggerProvider is used.
+func WithLoggerProvider(lp log.LoggerProvider) Option {
+	return func(cfg *SpanConfig) {
+		cfg.loggerProvider = lp
+	}
+}
+
+// WithLogOutput sets where records are written: to the slog.Logger, as OpenTelemetry log records or both.
+func WithLogOutput(output LogOutput) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logOutput = output
+	}
+}
+
+// WithBaggageAttrs enables or disables adding the OpenTelemetry baggage members of the context to span attributes,
+// e.g. the ones propagated by ContextWithBaggageAttrs from another process.
+// Baggage may come from untrusted clients, so it should only be enabled for trusted callers.
+func WithBaggageAttrs(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.baggageAttrs = v
+	}
+}
+
+// WithActionPath enables or disables the parent_action and action_path attributes of nested spans,
+// e.g. action_path=api.Handle>svc.Create>repo.Insert, so that the call tree can be reconstructed from logs.
+func WithActionPath(v bool) Option {
+	return func(cfg *SpanConfig) {
+		cfg.actionPath = v
+	}
+}
+
+// WithActionPathLimits sets the number of actions and the length in bytes of the action_path attribute.
+// When the path exceeds a limit, the outermost actions are replaced with "...", and an action longer than maxLength
+// is cut. Zero or a negative limit disables it.
+func WithActionPathLimits(maxDepth, maxLength int) Option {
+	return func(cfg *SpanConfig) {
+		cfg.actionPathMaxDepth = maxDepth
+		cfg.actionPathMaxLength = maxLength
+	}
+}
+
+// WithPanicMode sets what End does after recording a panic of the instrumented function.
+func WithPanicMode(mode PanicMode) Option {
+	return func(cfg *SpanConfig) {
+		cfg.panicMode = mode
+	}
+}
+
+// WithTraceLogFields sets the trace correlation fields added to records when tracing is enabled.
+// Defaults to DefaultTraceLogFields. Pass TraceLogFields{} to omit the fields.
+func WithTraceLogFields(fields TraceLogFields) Option {
+	return func(cfg *SpanConfig) {
+		cfg.traceLogFields = fields
 	}
+}
 
-	attrs := make([]slog.Attr, 0, 1+len(cfg.additionalAttrs))
-	attrs = append(attrs, slog.String("action", action))
-	attrs = append(attrs, cfg.additionalAttrs...)
+// WithLogSampler sets the LogSampler that decides which records are logged. If nil, all records are logged.
+// The rule that made the decision is added to logged records as the log_sampling attribute.
+func WithLogSampler(sampler LogSampler) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logSampler = sampler
+	}
+}
 
-	log(ctx, "action started", globalLogLevelEndOnSuccess.Level(), now, attrs...)
+// WithLogSuppressionReportInterval sets the minimum interval between two "log records suppressed" records
+// of the same action. Defaults to DefaultLogSuppressionReportInterval.
+// The report is logged along with the next record of the action after the interval, not on a timer,
+// use Tracer.FlushSuppressedLogs to report the remaining counts at shutdown.
+func WithLogSuppressionReportInterval(d time.Duration) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logSuppressionReportInterval = d
+	}
+}
 
-	return ctx, &span{
-		ctx:             ctx,
-		start:           now,
-		action:          action,
-		traceSpan:       otelSpan,
-		err:             cfg.err,
-		additionalAttrs: cfg.additionalAttrs,
+// WithTraceLogMode sets how the records of actions whose OpenTelemetry span is not sampled are logged, see TraceLogMode.
+func WithTraceLogMode(mode TraceLogMode) Option {
+	return func(cfg *SpanConfig) {
+		cfg.traceLogMode = mode
+	}
+}
+
+// WithLogLevelOnUnsampled sets the level of records downgraded by TraceLogModeDowngrade. Defaults to slog.LevelDebug.
+func WithLogLevelOnUnsampled(level slog.Level) Option {
+	return func(cfg *SpanConfig) {
+		cfg.logLevelOnUnsampled = level
 	}
 }
 
+// WithMetricAttrs promotes the attributes with the given keys to metric attributes.
+// The attributes are looked up in the attributes passed via WithAttrs and, for metrics recorded
+// when the span ends, also in the attributes added via AddAttrs.
+func WithMetricAttrs(keys ...string) Option {
+	return func(cfg *SpanConfig) {
+		cfg.metricAttrKeys = append(cfg.metricAttrKeys, keys...)
+	}
+}
+
+// WithMetricAttrCardinalityLimit sets the number of distinct values a promoted metric attribute key can have.
+// Once the limit is reached, new values are replaced with MetricAttrOverflowValue. Zero or a negative limit disables the guard.
+func WithMetricAttrCardinalityLimit(n int) Option {
+	return func(cfg *SpanConfig) {
+		cfg.metricAttrCardinalityLimit = n
+	}
+}
+
+// WithMetricNaming sets how metric instruments are named, see MetricNaming.
+func WithMetricNaming(naming MetricNaming) Option {
+	return func(cfg *SpanConfig) {
+		cfg.metricNaming = naming
+	}
+}
+
+// WithDurationBuckets sets the bucket boundaries of duration histograms, in the configured duration unit.
+// Boundaries are applied when a histogram is created, so they should be configured before the first span ends.
+func WithDurationBuckets(bounds ...float64) Option {
+	return func(cfg *SpanConfig) {
+		cfg.defaultDurationBuckets = bounds
+	}
+}
+
+// WithActionDurationBuckets sets the bucket boundaries of duration histograms for actions matching the pattern.
+// The pattern is either an exact action name or a pattern in the path.Match syntax, e.g. "cache.*".
+// Setting boundaries for the same pattern again replaces them.
+// It has no effect with MetricNamingShared, where all actions share a single histogram.
+func WithActionDurationBuckets(pattern string, bounds ...float64) Option {
+	return func(cfg *SpanConfig) {
+		cfg.actionDurationBuckets = slices.DeleteFunc(slices.Clone(cfg.actionDurationBuckets), func(b actionBuckets) bool {
+			return b.pattern == pattern
+		})
+		cfg.actionDurationBuckets = append(cfg.actionDurationBuckets, actionBuckets{pattern: pattern, bounds: bounds})
+	}
+}
+
+func (cfg *SpanConfig) startLogLevel() slog.Level {
+	if cfg.logLevelOnStart != nil {
+		return cfg.logLevelOnStart.Level()
+	}
+
+	return cfg.logLevelOnSuccess
+}
+
+func (cfg *SpanConfig) getTracerProvider() trace.TracerProvider {
+	if cfg.tracerProvider != nil {
+		return cfg.tracerProvider
+	}
+
+	return otel.GetTracerProvider()
+}
+
+func (cfg *SpanConfig) getMeterProvider() metric.MeterProvider {
+	if cfg.meterProvider != nil {
+		return cfg.meterProvider
+	}
+
+	return otel.GetMeterProvider()
+}
+
+// Span represents a traced and logged operation that can be ended.
+// The end record reports the caller of Start as its source,
+// because End is usually deferred and may run while a panic unwinds the stack.
+//
+// The error a span ends with is the one set via SetError or Fail, if any.
+// Otherwise, it is the error the pointer passed via WithErr points to.
+type Span interface {
+	End()
+	AddAttrs(attrs ...slog.Attr)
+	Event(name string, attrs ...slog.Attr)
+	// SetError sets the error the span ends with. The last call wins. Setting nil drops
+	// the error set before, so the span falls back to the error the WithErr pointer points to.
+	SetError(err error)
+	// Fail sets the error the span ends with and returns it, e.g. return span.Fail(err).
+	Fail(err error) error
+}
+
+type span struct {
+	ctx             context.Context
+	tracer          *Tracer
+	parent          *span
+	cfg             SpanConfig
+	start           time.Time
+	action          string
+	pc              uintptr
+	sampling        *SamplingDecision
+	traceSpan       trace.Span
+	activeCounter   metric.Int64UpDownCounter
+	activeAttrs     metric.MeasurementOption
+	contextAttrs    []slog.Attr
+	actionPathAttrs []slog.Attr
+	additionalAttrs []slog.Attr
+	err             error
+	mu              sync.RWMutex
+	ended           atomic.Bool
+}
+
+// Start begins a new traced and logged span for the given action using the default Tracer.
+// It returns an updated context and a Span that should be ended when the operation completes.
+func Start(ctx context.Context, action string, opts ...Option) (context.Context, Span) {
+	return Default().start(ctx, action, callerPC(1), opts)
+}
+
 // AddAttrs adds additional attributes to the span that will be logged when the span ends
 // and added to the OpenTelemetry span if it's recording. This method is thread-safe.
 func (s *span) AddAttrs(attrs ...slog.Attr) {
@@ -148,7 +482,7 @@ func (s *span) AddAttrs(attrs ...slog.Attr) {
 	s.additionalAttrs = append(s.additionalAttrs, attrs...)
 	s.mu.Unlock()
 
-	if s.traceSpan != nil && s.traceSpan.IsRecording() && globalAppendOtelAttrs.Load() {
+	if s.traceSpan != nil && s.traceSpan.IsRecording() && s.cfg.appendOtelAttrs {
 		otelAttrs := make([]attribute.KeyValue, 0, len(attrs))
 		for _, attr := range attrs {
 			otelAttrs = append(otelAttrs, mapSlogAttrToOtel(attr))
@@ -157,83 +491,228 @@ func (s *span) AddAttrs(attrs ...slog.Attr) {
 	}
 }
 
+// SetError sets the error the span ends with. This method is thread-safe.
+func (s *span) SetError(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Fail sets the error the span ends with and returns it. This method is thread-safe.
+func (s *span) Fail(err error) error {
+	s.SetError(err)
+	return err
+}
+
+// Event records an intermediate milestone of the action, such as "cache miss" or "retrying".
+// The event is added to the OpenTelemetry span if it's recording and logged with the time elapsed since the span started.
+func (s *span) Event(name string, attrs ...slog.Attr) {
+	s.event(name, callerPC(1), attrs)
+}
+
+func (s *span) event(name string, pc uintptr, attrs []slog.Attr) {
+	now := s.cfg.clock.Now()
+	elapsed := now.Sub(s.start)
+
+	elapsedAttr := slog.Float64("elapsed_ms", durationToMillisecond(elapsed))
+	if s.cfg.durationMetricUnit == DurationMetricUnitSecond {
+		elapsedAttr = slog.Float64("elapsed_s", durationToSecond(elapsed))
+	}
+
+	if s.traceSpan != nil && s.traceSpan.IsRecording() {
+		otelAttrs := make([]attribute.KeyValue, 0, len(attrs))
+		for _, attr := range attrs {
+			otelAttrs = append(otelAttrs, mapSlogAttrToOtel(attr))
+		}
+		s.traceSpan.AddEvent(name, trace.WithAttributes(otelAttrs...), trace.WithTimestamp(now))
+	}
+
+	logAttrs := make([]slog.Attr, 0, 3+len(attrs))
+	logAttrs = append(logAttrs, slog.String("action", s.action), slog.String("event", name), elapsedAttr)
+	logAttrs = append(logAttrs, attrs...)
+
+	s.tracer.logSampled(s.ctx, &s.cfg, SamplingParams{
+		Action:  s.action,
+		Message: "action event",
+		Level:   s.cfg.logLevelOnEvent,
+		Time:    now,
+	}, s.sampling, pc, logAttrs...)
+}
+
+// End ends the span. It logs the result of the action, records the duration metric and ends the OpenTelemetry span.
+//
+// When End is deferred directly (defer span.End()) and the function panics, the panic is recorded
+// as a *PanicError: the OpenTelemetry span gets the error with a stack trace, the record is logged
+// at the failure level with panic=true and the panic counter is incremented.
+// Afterward End either re-panics or converts the panic to an error, see PanicMode.
+// An error the span ends with that is a *PanicError, e.g. set by an integration that recovered the panic itself,
+// is recorded as a panic as well, but End doesn't panic in this case.
+//
+// Only the first call to End has an effect.
 func (s *span) End() {
+	if !s.ended.CompareAndSwap(false, true) {
+		return
+	}
+
+	recovered := recover()
+
 	if s.ctx == nil {
 		s.ctx = context.Background()
 	}
-	now := (*globalClock.Load()).Now()
+	now := s.cfg.clock.Now()
 	duration := now.Sub(s.start)
-	level := globalLogLevelEndOnSuccess.Level()
+	level := s.cfg.logLevelOnSuccess
 
-	durationMetricSuffix := "_duration_milliseconds"
 	durationAttrKey := "duration_ms"
 	durationAttrVal := durationToMillisecond(duration)
 
-	durationMetricUnit := globalDurationMetricUnit.Load()
-
-	if durationMetricUnit == DurationMetricUnitSecond {
+	if s.cfg.durationMetricUnit == DurationMetricUnitSecond {
 		durationAttrKey = "duration_s"
 		durationAttrVal = durationToSecond(duration)
-		durationMetricSuffix = "_duration_seconds"
 	}
 
 	s.mu.RLock()
-	attrs := make([]slog.Attr, 0, 2+len(s.additionalAttrs))
-	attrs = append(attrs, slog.String("action", s.action), slog.Float64(durationAttrKey, durationAttrVal))
-	attrs = append(attrs, s.additionalAttrs...)
+	spanAttrs := mergeAttrs(s.contextAttrs, s.additionalAttrs)
 	s.mu.RUnlock()
 
-	if s.err != nil && *s.err != nil {
-		level = globalLogLevelEndOnFailure.Level()
-		attrs = append(attrs, slog.Any("error", *s.err))
+	attrs := make([]slog.Attr, 0, 5+len(s.actionPathAttrs)+len(spanAttrs))
+	attrs = append(attrs, slog.String("action", s.action))
+	attrs = append(attrs, s.actionPathAttrs...)
+	attrs = append(attrs, slog.Float64(durationAttrKey, durationAttrVal))
+	attrs = append(attrs, spanAttrs...)
+
+	panicErr, err := s.endError(recovered)
+	if panicErr != nil {
+		attrs = append(attrs, slog.Bool("panic", true))
+	}
+
+	if err != nil {
+		level = s.cfg.logLevelOnFailure
+		attrs = append(attrs, slog.Any("error", err))
 
 		if s.traceSpan != nil {
-			s.traceSpan.RecordError(*s.err, trace.WithStackTrace(true))
-			s.traceSpan.SetStatus(codes.Error, (*s.err).Error())
+			s.traceSpan.RecordError(err, trace.WithStackTrace(true))
+			s.traceSpan.SetStatus(codes.Error, err.Error())
 		}
 	}
 
-	if globalMetricsEnabled.Load() {
-		metricName := s.action + durationMetricSuffix
-		histogram, ok := durationHistograms.Load(metricName)
-
-		if !ok {
-			var err error
-			histogram, err = otel.GetMeterProvider().
-				Meter(instrumentationName).
-				Float64Histogram(
-					metricName,
-					metric.WithUnit(durationMetricUnit),
-					metric.WithDescription(fmt.Sprintf("[%s] action duration", s.action)),
-				)
-			if err == nil {
-				durationHistograms.Store(metricName, histogram)
-				ok = true
-			}
-		}
+	slow := err == nil && s.isSlow(duration)
+	if slow {
+		level = s.cfg.logLevelOnSlow
+		attrs = append(attrs, slog.Bool(slowAttrKey, true))
 
-		if ok {
-			if durationMetricUnit == DurationMetricUnitSecond {
-				histogram.Record(s.ctx, duration.Seconds())
-			} else {
-				histogram.Record(s.ctx, durationToMillisecond(duration))
-			}
+		if s.traceSpan != nil {
+			s.traceSpan.SetAttributes(attribute.Bool(slowAttrKey, true))
 		}
 	}
 
-	log(s.ctx, "action ended", level, now, attrs...)
+	if s.activeCounter != nil {
+		s.activeCounter.Add(s.ctx, -1, s.activeAttrs)
+	}
+
+	if s.cfg.metricsEnabled {
+		s.recordMetrics(duration, attrs, err != nil, panicErr != nil, slow)
+	}
+
+	s.tracer.logSampled(s.ctx, &s.cfg, SamplingParams{
+		Action:  s.action,
+		Message: "action ended",
+		Level:   level,
+		Time:    now,
+		Failed:  err != nil,
+		Slow:    slow,
+	}, s.sampling, s.pc, attrs...)
 
 	if s.traceSpan != nil {
 		s.traceSpan.End(trace.WithTimestamp(now))
 	}
+
+	if recovered != nil {
+		if s.cfg.panicMode == PanicModeConvert && s.cfg.err != nil {
+			*s.cfg.err = panicErr
+			return
+		}
+
+		panic(recovered)
+	}
+}
+
+// endError returns the error the span ends with: the recovered panic, the error set via SetError or Fail,
+// or the error the WithErr pointer points to, in this order.
+func (s *span) endError(recovered any) (*PanicError, error) {
+	if recovered != nil {
+		panicErr := &PanicError{Value: recovered, Stack: debug.Stack()}
+		return panicErr, panicErr
+	}
+
+	s.mu.RLock()
+	err := s.err
+	s.mu.RUnlock()
+
+	if err == nil && s.cfg.err != nil {
+		err = *s.cfg.err
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return panicErr, err
+	}
+
+	return nil, err
+}
+
+// isSlow reports whether the duration exceeds the slow threshold.
+func (s *span) isSlow(duration time.Duration) bool {
+	return s.cfg.slowThreshold > 0 && duration > s.cfg.slowThreshold
+}
+
+// recordMetrics records the duration histogram and the outcome counter of the action.
+// Both carry the outcome attribute, so the error rate can be derived from either of them.
+func (s *span) recordMetrics(duration time.Duration, attrs []slog.Attr, failed, panicked, slow bool) {
+	mp := s.cfg.getMeterProvider()
+
+	outcome := outcomeSuccess
+	if failed {
+		outcome = outcomeError
+	}
+
+	names := s.cfg.metricNames(s.action)
+	metricAttrs := s.tracer.metricAttrs(&s.cfg, s.action, attrs)
+	attrSet := metric.WithAttributeSet(attribute.NewSet(metricAttrs...))
+	outcomeAttrs := metric.WithAttributeSet(attribute.NewSet(append(metricAttrs, attribute.String(outcomeAttrKey, outcome))...))
+
+	durationMetricValue := durationToMillisecond(duration)
+	if s.cfg.durationMetricUnit == DurationMetricUnitSecond {
+		durationMetricValue = durationToSecond(duration)
+	}
+
+	histogram, ok := s.tracer.durationHistogram(&s.cfg, mp, names, s.action)
+	if ok {
+		histogram.Record(s.ctx, durationMetricValue, outcomeAttrs)
+	}
+
+	if counter, ok := s.tracer.int64Counter(mp, names.outcomes); ok {
+		counter.Add(s.ctx, 1, outcomeAttrs)
+	}
+
+	if panicked {
+		if counter, ok := s.tracer.int64Counter(mp, names.panics); ok {
+			counter.Add(s.ctx, 1, attrSet)
+		}
+	}
+
+	if slow {
+		if counter, ok := s.tracer.int64Counter(mp, names.slow); ok {
+			counter.Add(s.ctx, 1, attrSet)
+		}
+	}
 }
 
-func log(ctx context.Context, msg string, level slog.Level, now time.Time, attrs ...slog.Attr) {
+// callerPC returns the program counter of the function skip frames above the caller of callerPC.
+func callerPC(skip int) uintptr {
 	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:])
-	r := slog.NewRecord(now, level, msg, pcs[0])
-	r.AddAttrs(attrs...)
-	_ = globalLogger.Load().Handler().Handle(ctx, r)
+	runtime.Callers(skip+2, pcs[:])
+	return pcs[0]
 }
 
 // mapSlogAttrToOtel converts a slog.Attr to an OpenTelemetry attribute.KeyValue.
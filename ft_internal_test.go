@@ -1,10 +1,14 @@
 package ft
 
 import (
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestDurationConversionPrecision(t *testing.T) {
@@ -16,3 +20,84 @@ func TestDurationConversionPrecision(t *testing.T) {
 	assert.InDelta(t, expectedMs, durationToMillisecond(d), 1e-12)
 	assert.InDelta(t, expectedSeconds, durationToSecond(d), 1e-12)
 }
+
+func TestInstrumentName(t *testing.T) {
+	tests := []struct {
+		action   string
+		expected string
+	}{
+		{action: "main.Do", expected: "main.Do_counter"},
+		{action: "svc/Get-User", expected: "svc/Get-User_counter"},
+		{action: "GET /users/{id}", expected: "GET_/users/_id__2d6ad7e0_counter"},
+		{action: "1st_action", expected: "a_1st_action_21e2b98e_counter"},
+		{action: "", expected: "a__811c9dc5_counter"},
+	}
+
+	for _, tt := range tests {
+		actual := instrumentName(tt.action, "_counter")
+		assert.Equal(t, tt.expected, actual)
+		assert.True(t, isValidInstrumentName(actual), actual)
+	}
+
+	assert.NotEqual(t, instrumentName("a b", "_counter"), instrumentName("a_b", "_counter"), "rewritten actions must not collide")
+
+	long := strings.Repeat("a", 300)
+	counter := instrumentName(long, "_counter")
+	duration := instrumentName(long, "_duration_milliseconds")
+	assert.Len(t, counter, maxInstrumentNameLength)
+	assert.Len(t, duration, maxInstrumentNameLength)
+	assert.True(t, strings.HasSuffix(counter, "_counter"), counter)
+	assert.True(t, strings.HasSuffix(duration, "_duration_milliseconds"), duration)
+	assert.True(t, isValidInstrumentName(duration), duration)
+}
+
+func TestTraceLogFieldsPresets(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2},
+		SpanID:     trace.SpanID{0, 0, 0, 0, 0, 0, 0, 3},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	datadog := DatadogTraceLogFields()
+	assert.Equal(t, []slog.Attr{
+		slog.String("dd.trace_id", "2"),
+		slog.String("dd.span_id", "3"),
+	}, datadog.attrs(sc))
+
+	gcp := GCPTraceLogFields("my-project")
+	assert.Equal(t, []slog.Attr{
+		slog.String("logging.googleapis.com/trace", "projects/my-project/traces/00000000000000010000000000000002"),
+		slog.String("logging.googleapis.com/spanId", "0000000000000003"),
+		slog.Bool("logging.googleapis.com/trace_sampled", true),
+	}, gcp.attrs(sc))
+
+	assert.Nil(t, gcp.attrs(trace.SpanContext{}))
+}
+
+func TestOtelSeverity(t *testing.T) {
+	assert.Equal(t, log.SeverityDebug, otelSeverity(slog.LevelDebug))
+	assert.Equal(t, log.SeverityInfo, otelSeverity(slog.LevelInfo))
+	assert.Equal(t, log.SeverityInfo2, otelSeverity(slog.LevelInfo+1))
+	assert.Equal(t, log.SeverityWarn, otelSeverity(slog.LevelWarn))
+	assert.Equal(t, log.SeverityError, otelSeverity(slog.LevelError))
+	assert.Equal(t, log.SeverityTrace1, otelSeverity(slog.LevelDebug-10))
+	assert.Equal(t, log.SeverityFatal4, otelSeverity(slog.LevelError+100))
+}
+
+func TestActionPath(t *testing.T) {
+	root := &span{action: "api.Handle"}
+	svc := &span{action: "svc.Create", parent: root}
+
+	assert.Equal(t, "api.Handle>svc.Create>repo.Insert", actionPath(svc, "repo.Insert", 0, 0))
+	assert.Equal(t, "...>svc.Create>repo.Insert", actionPath(svc, "repo.Insert", 2, 0))
+	assert.Equal(t, "...>svc.Create>repo.Insert", actionPath(svc, "repo.Insert", 0, len("...>svc.Create>repo.Insert")))
+	assert.Equal(t, "...>repo.Insert", actionPath(svc, "repo.Insert", 0, len("svc.Create>repo.Insert")))
+	assert.Equal(t, "repo.In...", actionPath(svc, "repo.Insert", 0, 10))
+	assert.Equal(t, "re", actionPath(svc, "repo.Insert", 0, 2))
+	assert.Equal(t, "...>abc", actionPath(&span{action: "def", parent: &span{action: "ghi"}}, "abc", 0, 10))
+	assert.Equal(t, "ä...", actionPath(nil, "äöüß", 0, 6), "the action is cut at a rune boundary")
+
+	for maxLength := 1; maxLength <= 40; maxLength++ {
+		assert.LessOrEqual(t, len(actionPath(svc, "repo.Insert", 0, maxLength)), maxLength)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -17,11 +19,15 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestSpan_Basic(t *testing.T) {
@@ -387,6 +393,1274 @@ func TestSpan_AddAttrs_Empty(t *testing.T) {
 	})
 }
 
+func TestTracer_Isolated(t *testing.T) {
+	t.Parallel()
+
+	var bufA, bufB testLogBuffer
+	fakeClock := clockwork.NewFakeClock()
+
+	tracerA := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&bufA, nil))),
+		ft.WithClock(fakeClock),
+	)
+	tracerB := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&bufB, nil))),
+		ft.WithClock(fakeClock),
+		ft.WithLogLevelOnSuccess(slog.LevelDebug),
+		ft.WithDurationMetricUnit(ft.DurationMetricUnitSecond),
+	)
+
+	_, span := tracerA.Start(context.Background(), "tracer_a")
+	fakeClock.Advance(time.Second)
+	span.End()
+
+	_, span = tracerB.Start(context.Background(), "tracer_b")
+	fakeClock.Advance(time.Second)
+	span.End()
+
+	assert.Contains(t, bufA.String(), "action=tracer_a")
+	assert.Contains(t, bufA.String(), "level=INFO")
+	assert.Contains(t, bufA.String(), "duration_ms=1000")
+	assert.NotContains(t, bufA.String(), "tracer_b")
+
+	assert.Contains(t, bufB.String(), "action=tracer_b")
+	assert.Contains(t, bufB.String(), "level=DEBUG")
+	assert.Contains(t, bufB.String(), "duration_s=1")
+	assert.NotContains(t, bufB.String(), "tracer_a")
+}
+
+func TestTracer_SetDefault(t *testing.T) {
+	prev := ft.Default()
+	t.Cleanup(func() { ft.SetDefault(prev) })
+
+	var logBuffer testLogBuffer
+	ft.SetDefault(ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, &slog.HandlerOptions{AddSource: true}))),
+		ft.WithAttrs(slog.String("service", "test")),
+	))
+
+	_, span := ft.Start(context.Background(), "test_default_tracer", ft.WithAttrs(slog.String("call", "1")))
+	span.End()
+
+	logOutput := logBuffer.String()
+	assert.Contains(t, logOutput, "service=test call=1")
+	assert.Contains(t, logOutput, "ft_test.go")
+}
+
+func TestTracer_ProviderInjection(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+
+	readerA := sdkmetric.NewManualReader()
+	mpA := sdkmetric.NewMeterProvider(sdkmetric.WithReader(readerA))
+	readerB := sdkmetric.NewManualReader()
+	mpB := sdkmetric.NewMeterProvider(sdkmetric.WithReader(readerB))
+
+	fakeClock := clockwork.NewFakeClock()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithClock(fakeClock),
+		ft.WithTracingEnabled(true),
+		ft.WithMetricsEnabled(true),
+		ft.WithTracerProvider(tp),
+		ft.WithMeterProvider(mpA),
+	)
+
+	ctx := context.Background()
+	testAction := "test_provider_injection"
+
+	_, span := tracer.Start(ctx, testAction)
+	fakeClock.Advance(10 * time.Millisecond)
+	span.End()
+
+	_, span = tracer.Start(ctx, testAction, ft.WithMeterProvider(mpB))
+	fakeClock.Advance(20 * time.Millisecond)
+	span.End()
+
+	require.Len(t, spanRecorder.Ended(), 2)
+
+	metricName := testAction + "_duration_milliseconds"
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, readerA.Collect(ctx, &rm))
+	histogram, ok := findHistogramMetric(rm, metricName)
+	require.True(t, ok, "expected histogram %s", metricName)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.InDelta(t, 10.0, histogram.DataPoints[0].Sum, 0.0001)
+
+	require.NoError(t, readerB.Collect(ctx, &rm))
+	histogram, ok = findHistogramMetric(rm, metricName)
+	require.True(t, ok, "expected histogram %s", metricName)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.InDelta(t, 20.0, histogram.DataPoints[0].Sum, 0.0001)
+}
+
+func TestSpan_PanicRepanic(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, &slog.HandlerOptions{AddSource: true}))),
+		ft.WithTracingEnabled(true),
+		ft.WithMetricsEnabled(true),
+		ft.WithTracerProvider(tp),
+		ft.WithMeterProvider(mp),
+	)
+
+	testAction := "test_panic_repanic"
+
+	require.PanicsWithValue(t, "boom", func() {
+		_, span := tracer.Start(context.Background(), testAction)
+		defer span.End()
+
+		panic("boom")
+	})
+
+	logOutput := logBuffer.String()
+	assert.Contains(t, logOutput, "level=ERROR")
+	assert.Contains(t, logOutput, "panic=true")
+	assert.Contains(t, logOutput, `error="panic: boom"`)
+	assert.Contains(t, logOutput, "ft_test.go")
+	assert.NotContains(t, logOutput, "runtime/panic.go", "the end record must not report the panicking frame")
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "panic: boom", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Contains(t, spans[0].Events()[0].Attributes, attribute.String("exception.message", "panic: boom"))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	counter, ok := findSumMetric(rm, testAction+"_panic_counter")
+	require.True(t, ok)
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, int64(1), counter.DataPoints[0].Value)
+}
+
+func TestSpan_PanicConvert(t *testing.T) {
+	t.Parallel()
+
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithPanicMode(ft.PanicModeConvert),
+	)
+
+	testErr := errors.New("test error")
+
+	do := func() (err error) {
+		_, span := tracer.Start(context.Background(), "test_panic_convert", ft.WithErr(&err))
+		defer span.End()
+
+		panic(testErr)
+	}
+
+	var err error
+	require.NotPanics(t, func() { err = do() })
+
+	var panicErr *ft.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, testErr, panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.ErrorIs(t, err, testErr)
+
+	require.Panics(t, func() {
+		_, span := tracer.Start(context.Background(), "test_panic_convert_without_err")
+		defer span.End()
+
+		panic("boom")
+	}, "panic must be propagated when WithErr is not used")
+}
+
+func TestRun(t *testing.T) {
+	prev := ft.Default()
+	t.Cleanup(func() { ft.SetDefault(prev) })
+
+	var logBuffer testLogBuffer
+	ft.SetDefault(ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, &slog.HandlerOptions{AddSource: true}))),
+		ft.WithPanicMode(ft.PanicModeConvert),
+	))
+
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	err := ft.Run(ctx, "test_run", func(ctx context.Context) error {
+		return testErr
+	}, ft.WithAttrs(slog.String("key", "value")))
+	require.ErrorIs(t, err, testErr)
+	assert.Contains(t, logBuffer.String(), "level=ERROR")
+	assert.Contains(t, logBuffer.String(), "key=value")
+	assert.Contains(t, logBuffer.String(), "ft_test.go")
+	assert.NotContains(t, logBuffer.String(), "run.go", "the end record must report the caller of Run")
+
+	logBuffer.Reset()
+	res, err := ft.Run1(ctx, "test_run1", func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, res)
+	assert.NotContains(t, logBuffer.String(), "level=ERROR")
+
+	res1, res2, err := ft.Run2(ctx, "test_run2", func(ctx context.Context) (string, bool, error) {
+		return "value", true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "value", res1)
+	assert.True(t, res2)
+
+	logBuffer.Reset()
+	_, err = ft.Run1(ctx, "test_run1_panic", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	var panicErr *ft.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.Contains(t, logBuffer.String(), "panic=true")
+}
+
+func TestTracer_Run(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithTracingEnabled(true),
+		ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))),
+	)
+
+	testErr := errors.New("test error")
+	err := tracer.Run(context.Background(), "test_tracer_run", func(ctx context.Context) error {
+		assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+		return testErr
+	})
+	require.ErrorIs(t, err, testErr)
+
+	res, err := ft.RunWith1(tracer, context.Background(), "test_tracer_run1", func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, res)
+
+	res1, res2, err := ft.RunWith2(tracer, context.Background(), "test_tracer_run2", func(ctx context.Context) (string, bool, error) {
+		return "value", true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "value", res1)
+	assert.True(t, res2)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "test_tracer_run1", spans[1].Name())
+	assert.Equal(t, "test_tracer_run2", spans[2].Name())
+}
+
+func TestSpan_OutcomeMetrics(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	ctx := context.Background()
+	testAction := "test_outcome_metrics"
+
+	for i := 0; i < 3; i++ {
+		_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return nil })
+	}
+	_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return errors.New("test error") })
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	outcomes := map[string]int64{}
+	counter, ok := findSumMetric(rm, testAction+"_outcome_counter")
+	require.True(t, ok)
+	for _, dp := range counter.DataPoints {
+		outcome, _ := dp.Attributes.Value("outcome")
+		outcomes[outcome.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"success": 3, "error": 1}, outcomes)
+
+	outcomes = map[string]int64{}
+	histogram, ok := findHistogramMetric(rm, testAction+"_duration_milliseconds")
+	require.True(t, ok)
+	for _, dp := range histogram.DataPoints {
+		outcome, _ := dp.Attributes.Value("outcome")
+		outcomes[outcome.AsString()] = int64(dp.Count)
+	}
+	assert.Equal(t, map[string]int64{"success": 3, "error": 1}, outcomes)
+}
+
+func TestSpan_ActiveMetric(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	ctx := context.Background()
+	testAction := "test_active_metric"
+	metricName := testAction + "_active"
+
+	active := func() int64 {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &rm))
+		counter, ok := findSumMetric(rm, metricName)
+		require.True(t, ok, "expected up-down counter %s", metricName)
+		require.Len(t, counter.DataPoints, 1)
+		assert.False(t, counter.IsMonotonic)
+		return counter.DataPoints[0].Value
+	}
+
+	_, span1 := tracer.Start(ctx, testAction)
+	_, span2 := tracer.Start(ctx, testAction)
+	assert.Equal(t, int64(2), active())
+
+	span1.End()
+	span1.End()
+	assert.Equal(t, int64(1), active())
+
+	span2.End()
+	assert.Equal(t, int64(0), active())
+}
+
+func TestSpan_MetricAttrs(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		ft.WithMetricAttrs("tenant"),
+		ft.WithMetricAttrCardinalityLimit(2),
+	)
+
+	ctx := context.Background()
+	testAction := "test_metric_attrs"
+
+	for _, tenant := range []string{"a", "b", "a", "c", "d"} {
+		_, span := tracer.Start(ctx, testAction,
+			ft.WithAttrs(slog.String("tenant", tenant), slog.String("user_id", "1")),
+			ft.WithMetricAttrs("region"),
+		)
+		span.AddAttrs(slog.String("region", "eu"))
+		span.End()
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	calls := map[string]int64{}
+	counter, ok := findSumMetric(rm, testAction+"_counter")
+	require.True(t, ok)
+	for _, dp := range counter.DataPoints {
+		tenant, _ := dp.Attributes.Value("tenant")
+		calls[tenant.AsString()] = dp.Value
+		assert.False(t, dp.Attributes.HasValue("user_id"))
+		assert.False(t, dp.Attributes.HasValue("region"), "attributes added after start are not known to the call counter")
+	}
+	assert.Equal(t, map[string]int64{"a": 2, "b": 1, ft.MetricAttrOverflowValue: 2}, calls)
+
+	calls = map[string]int64{}
+	counter, ok = findSumMetric(rm, testAction+"_outcome_counter")
+	require.True(t, ok)
+	for _, dp := range counter.DataPoints {
+		tenant, _ := dp.Attributes.Value("tenant")
+		region, _ := dp.Attributes.Value("region")
+		assert.Equal(t, "eu", region.AsString())
+		calls[tenant.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"a": 2, "b": 1, ft.MetricAttrOverflowValue: 2}, calls)
+
+	counter, ok = findSumMetric(rm, testAction+"_active")
+	require.True(t, ok)
+	for _, dp := range counter.DataPoints {
+		assert.Equal(t, int64(0), dp.Value)
+	}
+}
+
+func TestSpan_SharedMetricNaming(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		ft.WithMetricNaming(ft.MetricNamingShared),
+	)
+
+	ctx := context.Background()
+
+	_ = tracer.Run(ctx, "svc.Get", func(ctx context.Context) error { return nil })
+	_ = tracer.Run(ctx, "svc.Get", func(ctx context.Context) error { return nil })
+	_ = tracer.Run(ctx, "svc.Create", func(ctx context.Context) error { return errors.New("test error") })
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	for _, scopeMetrics := range rm.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			assert.Contains(t, []string{"ft.calls", "ft.outcomes", "ft.active", "ft.duration"}, m.Name)
+		}
+	}
+
+	calls := map[string]int64{}
+	counter, ok := findSumMetric(rm, "ft.calls")
+	require.True(t, ok)
+	for _, dp := range counter.DataPoints {
+		action, _ := dp.Attributes.Value("action")
+		calls[action.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"svc.Get": 2, "svc.Create": 1}, calls)
+
+	histogram, ok := findHistogramMetric(rm, "ft.duration")
+	require.True(t, ok)
+	assert.Len(t, histogram.DataPoints, 2)
+	for _, dp := range histogram.DataPoints {
+		action, _ := dp.Attributes.Value("action")
+		outcome, _ := dp.Attributes.Value("outcome")
+		if action.AsString() == "svc.Create" {
+			assert.Equal(t, "error", outcome.AsString())
+		} else {
+			assert.Equal(t, "success", outcome.AsString())
+		}
+	}
+}
+
+func TestSpan_DurationBuckets(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		ft.WithDurationBuckets(10, 100, 1000),
+		ft.WithActionDurationBuckets("cache.*", 0.1, 0.5, 1),
+		ft.WithActionDurationBuckets("cache.Get", 0.01, 0.05),
+	)
+
+	ctx := context.Background()
+	for _, action := range []string{"cache.Get", "cache.Set", "batch.Run"} {
+		_ = tracer.Run(ctx, action, func(ctx context.Context) error { return nil })
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	expected := map[string][]float64{
+		"cache.Get_duration_milliseconds": {0.01, 0.05},
+		"cache.Set_duration_milliseconds": {0.1, 0.5, 1},
+		"batch.Run_duration_milliseconds": {10, 100, 1000},
+	}
+	for name, bounds := range expected {
+		histogram, ok := findHistogramMetric(rm, name)
+		require.True(t, ok, "expected histogram %s", name)
+		require.Len(t, histogram.DataPoints, 1)
+		assert.Equal(t, bounds, histogram.DataPoints[0].Bounds, name)
+	}
+}
+
+func TestSpan_Event(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	fakeClock := clockwork.NewFakeClock()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+		ft.WithClock(fakeClock),
+		ft.WithTracingEnabled(true),
+		ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))),
+		ft.WithLogLevelOnEvent(slog.LevelDebug),
+	)
+
+	_, span := tracer.Start(context.Background(), "test_event")
+	fakeClock.Advance(25 * time.Millisecond)
+	span.Event("cache miss", slog.String("key", "user:1"))
+	span.End()
+
+	assert.Contains(t, logBuffer.String(),
+		`level=DEBUG msg="action event" action=test_event event="cache miss" elapsed_ms=25 key=user:1`)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events(), 1)
+
+	event := spans[0].Events()[0]
+	assert.Equal(t, "cache miss", event.Name)
+	assert.Equal(t, spans[0].StartTime().Add(25*time.Millisecond), event.Time)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("key", "user:1")}, event.Attributes)
+}
+
+func TestSpan_SetError(t *testing.T) {
+	t.Parallel()
+
+	newTracer := func(logBuffer *testLogBuffer) *ft.Tracer {
+		return ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+		)
+	}
+
+	t.Run("fail without named return", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+		expectedErr := errors.New("fail error")
+
+		err := func() error {
+			_, span := tracer.Start(context.Background(), "test_fail")
+			defer span.End()
+
+			return span.Fail(expectedErr)
+		}()
+
+		require.ErrorIs(t, err, expectedErr)
+		assert.Contains(t, logBuffer.String(), `level=ERROR msg="action ended" action=test_fail duration_ms=0 error="fail error"`)
+	})
+
+	t.Run("explicit error takes precedence over pointer", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+		ptrErr := errors.New("pointer error")
+
+		_, span := tracer.Start(context.Background(), "test_precedence", ft.WithErr(&ptrErr))
+		span.SetError(errors.New("explicit error"))
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `error="explicit error"`)
+		assert.NotContains(t, logBuffer.String(), "pointer error")
+	})
+
+	t.Run("nil clears error", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+
+		_, span := tracer.Start(context.Background(), "test_clear")
+		span.SetError(errors.New("temporary error"))
+		span.SetError(nil)
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `level=INFO msg="action ended" action=test_clear duration_ms=0`)
+		assert.NotContains(t, logBuffer.String(), "temporary error")
+	})
+
+	t.Run("nil falls back to pointer", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+		ptrErr := errors.New("pointer error")
+
+		_, span := tracer.Start(context.Background(), "test_fallback", ft.WithErr(&ptrErr))
+		span.SetError(errors.New("temporary error"))
+		span.SetError(nil)
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `error="pointer error"`)
+		assert.NotContains(t, logBuffer.String(), "temporary error")
+	})
+}
+
+func TestSpan_SlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	fakeClock := clockwork.NewFakeClock()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+		ft.WithClock(fakeClock),
+		ft.WithTracingEnabled(true),
+		ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))),
+		ft.WithMetricsEnabled(true),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		ft.WithTraceLogFields(ft.TraceLogFields{}),
+		ft.WithSlowThreshold(100*time.Millisecond),
+	)
+
+	ctx := context.Background()
+	testAction := "test_slow"
+
+	_, span := tracer.Start(ctx, testAction)
+	fakeClock.Advance(50 * time.Millisecond)
+	span.End()
+
+	_, span = tracer.Start(ctx, testAction)
+	fakeClock.Advance(150 * time.Millisecond)
+	span.End()
+
+	_, span = tracer.Start(ctx, testAction)
+	fakeClock.Advance(150 * time.Millisecond)
+	span.SetError(errors.New("test error"))
+	span.End()
+
+	_, span = tracer.Start(ctx, testAction, ft.WithSlowThreshold(time.Second))
+	fakeClock.Advance(150 * time.Millisecond)
+	span.End()
+
+	logs := logBuffer.String()
+	assert.Contains(t, logs, `level=INFO msg="action ended" action=test_slow duration_ms=50`+"\n")
+	assert.Contains(t, logs, `level=WARN msg="action ended" action=test_slow duration_ms=150 slow=true`+"\n")
+	assert.Contains(t, logs, `level=ERROR msg="action ended" action=test_slow duration_ms=150 error="test error"`+"\n")
+	assert.Contains(t, logs, `level=INFO msg="action ended" action=test_slow duration_ms=150`+"\n")
+	assert.Equal(t, 1, strings.Count(logs, "slow=true"))
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Contains(t, spans[1].Attributes(), attribute.Bool("slow", true))
+	assert.NotContains(t, spans[2].Attributes(), attribute.Bool("slow", true))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	counter, ok := findSumMetric(rm, testAction+"_slow_counter")
+	require.True(t, ok)
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, int64(1), counter.DataPoints[0].Value)
+}
+
+func TestSpan_StartLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("own level", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		var startLevel slog.LevelVar
+		startLevel.Set(slog.LevelDebug)
+
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithLogLevelOnStart(&startLevel),
+		)
+
+		_, span := tracer.Start(context.Background(), "test_start_level")
+		span.End()
+
+		startLevel.Set(slog.LevelWarn)
+		_, span = tracer.Start(context.Background(), "test_start_level")
+		span.End()
+
+		logs := logBuffer.String()
+		assert.Contains(t, logs, `level=DEBUG msg="action started" action=test_start_level`)
+		assert.Contains(t, logs, `level=WARN msg="action started" action=test_start_level`)
+		assert.Equal(t, 2, strings.Count(logs, `level=INFO msg="action ended" action=test_start_level`))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithStartLogEnabled(false),
+		)
+
+		_, span := tracer.Start(context.Background(), "test_start_disabled")
+		span.End()
+
+		assert.NotContains(t, logBuffer.String(), "action started")
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_start_disabled`)
+	})
+
+	t.Run("disabled per call", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+		)
+
+		_, span := tracer.Start(context.Background(), "test_without_start_log", ft.WithoutStartLog())
+		span.End()
+		_, span = tracer.Start(context.Background(), "test_with_start_log")
+		span.End()
+
+		logs := logBuffer.String()
+		assert.NotContains(t, logs, `msg="action started" action=test_without_start_log`)
+		assert.Contains(t, logs, `msg="action ended" action=test_without_start_log`)
+		assert.Contains(t, logs, `msg="action started" action=test_with_start_log`)
+	})
+}
+
+func TestSpan_LogSampler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("count sampler", func(t *testing.T) {
+		t.Parallel()
+
+		reader := sdkmetric.NewManualReader()
+		fakeClock := clockwork.NewFakeClock()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(fakeClock),
+			ft.WithoutStartLog(),
+			ft.WithMetricsEnabled(true),
+			ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+			ft.WithLogSampler(ft.NewCountSampler(time.Minute, 2, 3)),
+			ft.WithLogSuppressionReportInterval(time.Minute),
+		)
+
+		ctx := context.Background()
+		testAction := "test_count_sampler"
+
+		for i := 0; i < 8; i++ {
+			_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return nil })
+		}
+
+		logs := logBuffer.String()
+		assert.Equal(t, 2, strings.Count(logs, "log_sampling=first"))
+		assert.Equal(t, 2, strings.Count(logs, "log_sampling=thereafter"))
+		assert.NotContains(t, logs, "log records suppressed")
+
+		fakeClock.Advance(time.Minute)
+		_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return nil })
+
+		logs = logBuffer.String()
+		assert.Contains(t, logs, `level=INFO msg="log records suppressed" action=test_count_sampler suppressed=4`)
+		assert.Equal(t, 3, strings.Count(logs, "log_sampling=first"))
+
+		for i := 0; i < 2; i++ {
+			_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return nil })
+		}
+		assert.NotContains(t, logBuffer.String(), "suppressed=1")
+
+		tracer.FlushSuppressedLogs(ctx)
+		assert.Contains(t, logBuffer.String(), `level=INFO msg="log records suppressed" action=test_count_sampler suppressed=1`)
+
+		logBuffer.Reset()
+		tracer.FlushSuppressedLogs(ctx)
+		assert.Empty(t, logBuffer.String(), "flushed counts must not be reported twice")
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &rm))
+
+		counter, ok := findSumMetric(rm, testAction+"_outcome_counter")
+		require.True(t, ok)
+		require.Len(t, counter.DataPoints, 1)
+		assert.Equal(t, int64(11), counter.DataPoints[0].Value)
+	})
+
+	t.Run("keep failures and slow calls", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClock := clockwork.NewFakeClock()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(fakeClock),
+			ft.WithSlowThreshold(time.Second),
+			ft.WithLogSampler(ft.NewKeepFailuresSampler(ft.NewProbabilitySampler(0))),
+		)
+
+		ctx := context.Background()
+
+		_ = tracer.Run(ctx, "test_keep_success", func(ctx context.Context) error { return nil })
+		_ = tracer.Run(ctx, "test_keep_failure", func(ctx context.Context) error { return errors.New("test error") })
+		_ = tracer.Run(ctx, "test_keep_slow", func(ctx context.Context) error {
+			fakeClock.Advance(2 * time.Second)
+			return nil
+		})
+
+		logs := logBuffer.String()
+		assert.NotContains(t, logs, "action started")
+		assert.NotContains(t, logs, "action=test_keep_success")
+		assert.Contains(t, logs, `level=ERROR msg="action ended" action=test_keep_failure duration_ms=0 error="test error" log_sampling=failure`)
+		assert.Contains(t, logs, `level=WARN msg="action ended" action=test_keep_slow duration_ms=2000 slow=true log_sampling=slow`)
+	})
+
+	t.Run("decision per span", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithLogSampler(ft.NewKeepFailuresSampler(ft.NewCountSampler(time.Minute, 1, 0))),
+		)
+
+		ctx := context.Background()
+		testAction := "test_span_sampling"
+
+		_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return nil })
+		_ = tracer.Run(ctx, testAction, func(ctx context.Context) error {
+			ft.Event(ctx, "step")
+			return nil
+		})
+		_ = tracer.Run(ctx, testAction, func(ctx context.Context) error { return errors.New("test error") })
+
+		logs := logBuffer.String()
+		assert.Equal(t, 1, strings.Count(logs, "action started"))
+		assert.NotContains(t, logs, "action event", "the event shares the decision of the second span")
+		assert.Equal(t, 2, strings.Count(logs, "log_sampling=first"))
+		assert.Contains(t, logs, `level=ERROR msg="action ended" action=test_span_sampling duration_ms=0 error="test error" log_sampling=failure`)
+	})
+}
+
+func TestSpan_TraceLogMode(t *testing.T) {
+	t.Parallel()
+
+	newTracer := func(logBuffer *testLogBuffer, opts ...ft.Option) *ft.Tracer {
+		return ft.New(append([]ft.Option{
+			ft.WithLogger(slog.New(slog.NewTextHandler(logBuffer, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))),
+		}, opts...)...)
+	}
+
+	run := func(tracer *ft.Tracer) {
+		ctx := context.Background()
+		_ = tracer.Run(ctx, "test_unsampled_success", func(ctx context.Context) error { return nil })
+		_ = tracer.Run(ctx, "test_unsampled_failure", func(ctx context.Context) error { return errors.New("test error") })
+	}
+
+	t.Run("suppress", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		run(newTracer(&logBuffer, ft.WithTracingEnabled(true), ft.WithTraceLogMode(ft.TraceLogModeSuppress)))
+
+		logs := logBuffer.String()
+		assert.NotContains(t, logs, "action=test_unsampled_success")
+		assert.NotContains(t, logs, `msg="action started" action=test_unsampled_failure`)
+		assert.Contains(t, logs, `level=ERROR msg="action ended" action=test_unsampled_failure duration_ms=0 error="test error"`)
+	})
+
+	t.Run("downgrade", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		run(newTracer(&logBuffer, ft.WithTracingEnabled(true), ft.WithTraceLogMode(ft.TraceLogModeDowngrade)))
+
+		logs := logBuffer.String()
+		assert.Contains(t, logs, `level=DEBUG msg="action started" action=test_unsampled_success`)
+		assert.Contains(t, logs, `level=DEBUG msg="action ended" action=test_unsampled_success duration_ms=0`)
+		assert.Contains(t, logs, `level=ERROR msg="action ended" action=test_unsampled_failure duration_ms=0 error="test error"`)
+	})
+
+	t.Run("tracing disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		run(newTracer(&logBuffer, ft.WithTracingEnabled(false), ft.WithTraceLogMode(ft.TraceLogModeSuppress)))
+
+		logs := logBuffer.String()
+		assert.Contains(t, logs, `level=INFO msg="action started" action=test_unsampled_success`)
+		assert.Contains(t, logs, `level=INFO msg="action ended" action=test_unsampled_success duration_ms=0`)
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer,
+			ft.WithTracingEnabled(true),
+			ft.WithTraceLogMode(ft.TraceLogModeSuppress),
+			ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))),
+		)
+		run(tracer)
+
+		assert.Contains(t, logBuffer.String(), `level=INFO msg="action ended" action=test_unsampled_success duration_ms=0`)
+	})
+}
+
+func TestSpan_TraceLogFields(t *testing.T) {
+	t.Parallel()
+
+	newTracer := func(logBuffer *testLogBuffer, opts ...ft.Option) *ft.Tracer {
+		return ft.New(append([]ft.Option{
+			ft.WithLogger(slog.New(slog.NewTextHandler(logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithTracingEnabled(true),
+			ft.WithTracerProvider(sdktrace.NewTracerProvider()),
+		}, opts...)...)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+
+		ctx, span := tracer.Start(context.Background(), "test_trace_fields")
+		span.End()
+
+		sc := trace.SpanContextFromContext(ctx)
+		fields := fmt.Sprintf("trace_id=%s span_id=%s\n", sc.TraceID(), sc.SpanID())
+		assert.Contains(t, logBuffer.String(), `msg="action started" action=test_trace_fields `+fields)
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_trace_fields duration_ms=0 `+fields)
+	})
+
+	t.Run("custom keys", func(t *testing.T) {
+		t.Parallel()
+
+		fields := ft.ECSTraceLogFields()
+		fields.TraceFlagsKey = "trace.flags"
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer, ft.WithTraceLogFields(fields))
+
+		ctx, span := tracer.Start(context.Background(), "test_trace_fields")
+		span.End()
+
+		sc := trace.SpanContextFromContext(ctx)
+		assert.Contains(t, logBuffer.String(), fmt.Sprintf("trace.id=%s span.id=%s trace.flags=01\n", sc.TraceID(), sc.SpanID()))
+		assert.NotContains(t, logBuffer.String(), "trace_id")
+	})
+
+	t.Run("tracing disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer, ft.WithTracingEnabled(false))
+
+		_, span := tracer.Start(context.Background(), "test_trace_fields")
+		span.End()
+
+		assert.NotContains(t, logBuffer.String(), "trace_id")
+	})
+
+	t.Run("log handler", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		logger := slog.New(ft.NewLogHandler(slog.NewTextHandler(&logBuffer, nil), ft.DefaultTraceLogFields()))
+		tracer := newTracer(&logBuffer, ft.WithLogger(logger))
+
+		ctx, span := tracer.Start(context.Background(), "test_trace_fields")
+		logger.With(slog.String("key", "value")).InfoContext(ctx, "custom record")
+		logger.InfoContext(context.Background(), "record without span")
+		span.End()
+
+		sc := trace.SpanContextFromContext(ctx)
+		fields := fmt.Sprintf("trace_id=%s span_id=%s\n", sc.TraceID(), sc.SpanID())
+		assert.Contains(t, logBuffer.String(), `msg="custom record" key=value `+fields)
+		assert.Contains(t, logBuffer.String(), `msg="record without span"`+"\n")
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_trace_fields duration_ms=0 `+fields)
+	})
+}
+
+func TestSpan_LogOutput(t *testing.T) {
+	t.Parallel()
+
+	newTracer := func(logBuffer *testLogBuffer, exporter *testLogExporter, output ft.LogOutput) *ft.Tracer {
+		return ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithTracingEnabled(true),
+			ft.WithTracerProvider(sdktrace.NewTracerProvider()),
+			ft.WithLoggerProvider(sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))),
+			ft.WithLogOutput(output),
+		)
+	}
+
+	t.Run("otel", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		var exporter testLogExporter
+		tracer := newTracer(&logBuffer, &exporter, ft.LogOutputOtel)
+
+		ctx, span := tracer.Start(context.Background(), "test_otel_logs", ft.WithAttrs(slog.Int("attempt", 2)))
+		span.SetError(errors.New("test error"))
+		span.End()
+
+		assert.Empty(t, logBuffer.String())
+
+		records := exporter.Records()
+		require.Len(t, records, 2)
+
+		sc := trace.SpanContextFromContext(ctx)
+		for _, r := range records {
+			assert.Equal(t, sc.TraceID(), r.TraceID())
+			assert.Equal(t, sc.SpanID(), r.SpanID())
+		}
+
+		assert.Equal(t, "action started", records[0].Body().AsString())
+		assert.Equal(t, otellog.SeverityInfo, records[0].Severity())
+		assert.Equal(t, "INFO", records[0].SeverityText())
+
+		ended := records[1]
+		assert.Equal(t, "action ended", ended.Body().AsString())
+		assert.Equal(t, otellog.SeverityError, ended.Severity())
+		assert.Equal(t, "ERROR", ended.SeverityText())
+
+		attrs := map[string]otellog.Value{}
+		ended.WalkAttributes(func(kv otellog.KeyValue) bool {
+			attrs[kv.Key] = kv.Value
+			return true
+		})
+		assert.Equal(t, "test_otel_logs", attrs["action"].AsString())
+		assert.Equal(t, int64(2), attrs["attempt"].AsInt64())
+		assert.Equal(t, "test error", attrs["error"].AsString())
+		assert.NotContains(t, attrs, "trace_id")
+	})
+
+	t.Run("both", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		var exporter testLogExporter
+		tracer := newTracer(&logBuffer, &exporter, ft.LogOutputBoth)
+
+		_, span := tracer.Start(context.Background(), "test_otel_logs")
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_otel_logs`)
+		assert.Len(t, exporter.Records(), 2)
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		var exporter testLogExporter
+		tracer := newTracer(&logBuffer, &exporter, ft.LogOutputSlog)
+
+		_, span := tracer.Start(context.Background(), "test_otel_logs")
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_otel_logs`)
+		assert.Empty(t, exporter.Records())
+	})
+}
+
+func TestSpanFromContext(t *testing.T) {
+	t.Parallel()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, &slog.HandlerOptions{AddSource: true}))),
+		ft.WithClock(clockwork.NewFakeClock()),
+	)
+
+	ctx, span := tracer.Start(context.Background(), "test_span_from_context")
+	assert.Same(t, span, ft.SpanFromContext(ctx))
+
+	ft.AddAttrs(ctx, slog.String("user_id", "42"))
+	ft.Event(ctx, "cache miss", slog.String("key", "user:42"))
+	span.End()
+
+	logs := logBuffer.String()
+	assert.Contains(t, logs, `msg="action event" action=test_span_from_context event="cache miss" elapsed_ms=0 key=user:42`)
+	assert.Contains(t, logs, `msg="action ended" action=test_span_from_context duration_ms=0 user_id=42`)
+	assert.Contains(t, logs, "source=")
+	assert.NotContains(t, logs, "context.go")
+
+	t.Run("no span", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		span := ft.SpanFromContext(ctx)
+		require.NotNil(t, span)
+
+		ft.AddAttrs(ctx, slog.String("key", "value"))
+		ft.Event(ctx, "event")
+		span.SetError(errors.New("ignored"))
+		span.End()
+
+		expectedErr := errors.New("test error")
+		assert.Equal(t, expectedErr, span.Fail(expectedErr))
+	})
+}
+
+func TestContextWithAttrs(t *testing.T) {
+	t.Parallel()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+		ft.WithClock(clockwork.NewFakeClock()),
+		ft.WithTracingEnabled(true),
+		ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))),
+		ft.WithAppendOtelAttrs(true),
+		ft.WithTraceLogFields(ft.TraceLogFields{}),
+	)
+
+	ctx := ft.ContextWithAttrs(context.Background(), slog.String("request_id", "r1"), slog.String("tenant", "t1"))
+	ctx = ft.ContextWithAttrs(ctx, slog.String("user_id", "u1"), slog.String("tenant", "t2"))
+
+	ctx, parent := tracer.Start(ctx, "test_parent")
+	_, child := tracer.Start(ctx, "test_child", ft.WithAttrs(slog.String("user_id", "u2")))
+	child.AddAttrs(slog.String("request_id", "r2"))
+	child.End()
+	parent.End()
+
+	logs := logBuffer.String()
+	assert.Contains(t, logs, `msg="action started" action=test_parent request_id=r1 user_id=u1 tenant=t2`+"\n")
+	assert.Contains(t, logs, `msg="action ended" action=test_parent duration_ms=0 request_id=r1 user_id=u1 tenant=t2`+"\n")
+	assert.Contains(t, logs, `msg="action started" action=test_child request_id=r1 tenant=t2 user_id=u2`+"\n")
+	assert.Contains(t, logs, `msg="action ended" action=test_child duration_ms=0 tenant=t2 user_id=u2 request_id=r2`+"\n")
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("tenant", "t2"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("request_id", "r2"))
+	assert.NotContains(t, spans[0].Attributes(), attribute.String("request_id", "r1"))
+}
+
+func TestContextWithBaggageAttrs(t *testing.T) {
+	t.Parallel()
+
+	propagator := propagation.Baggage{}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ft.ContextWithBaggageAttrs(context.Background(), slog.String("tenant", "t1"), slog.Int("shard", 3)), carrier)
+
+	newTracer := func(logBuffer *testLogBuffer, opts ...ft.Option) *ft.Tracer {
+		return ft.New(append([]ft.Option{
+			ft.WithLogger(slog.New(slog.NewTextHandler(logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+		}, opts...)...)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer, ft.WithBaggageAttrs(true))
+
+		ctx := propagator.Extract(context.Background(), carrier)
+		ctx = ft.ContextWithAttrs(ctx, slog.String("tenant", "local"))
+		_, span := tracer.Start(ctx, "test_baggage")
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_baggage duration_ms=0 shard=3 tenant=local`+"\n")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := newTracer(&logBuffer)
+
+		_, span := tracer.Start(propagator.Extract(context.Background(), carrier), "test_baggage")
+		span.End()
+
+		assert.Contains(t, logBuffer.String(), `msg="action ended" action=test_baggage duration_ms=0`+"\n")
+	})
+}
+
+func TestSpan_ActionPath(t *testing.T) {
+	t.Parallel()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+		ft.WithClock(clockwork.NewFakeClock()),
+		ft.WithActionPath(true),
+	)
+
+	ctx, root := tracer.Start(context.Background(), "api.Handle")
+	ctx, svc := tracer.Start(ctx, "svc.Create")
+	_, repo := tracer.Start(ctx, "repo.Insert", ft.WithAttrs(slog.String("table", "users")))
+	repo.End()
+	svc.End()
+	root.End()
+
+	logs := logBuffer.String()
+	assert.Contains(t, logs, `msg="action started" action=api.Handle`+"\n")
+	assert.Contains(t, logs, `msg="action started" action=svc.Create parent_action=api.Handle action_path=api.Handle>svc.Create`+"\n")
+	assert.Contains(t, logs, `msg="action started" action=repo.Insert parent_action=svc.Create action_path=api.Handle>svc.Create>repo.Insert table=users`+"\n")
+	assert.Contains(t, logs, `msg="action ended" action=repo.Insert parent_action=svc.Create action_path=api.Handle>svc.Create>repo.Insert duration_ms=0 table=users`+"\n")
+	assert.Contains(t, logs, `msg="action ended" action=api.Handle duration_ms=0`+"\n")
+
+	t.Run("limits", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer testLogBuffer
+		tracer := ft.New(
+			ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+			ft.WithClock(clockwork.NewFakeClock()),
+			ft.WithActionPath(true),
+			ft.WithActionPathLimits(3, 0),
+		)
+
+		ctx := context.Background()
+		for i := 0; i < 5; i++ {
+			var span ft.Span
+			ctx, span = tracer.Start(ctx, fmt.Sprintf("recurse%d", i))
+			defer span.End()
+		}
+
+		assert.Contains(t, logBuffer.String(), `msg="action started" action=recurse4 parent_action=recurse3 action_path=...>recurse2>recurse3>recurse4`+"\n")
+	})
+}
+
+func TestStartTime(t *testing.T) {
+	t.Parallel()
+
+	var buf testLogBuffer
+	fakeClock := clockwork.NewFakeClock()
+
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		ft.WithClock(fakeClock),
+		ft.WithTraceLogFields(ft.TraceLogFields{}),
+	)
+
+	start := tracer.Now()
+	fakeClock.Advance(2 * time.Second)
+
+	_, span := tracer.Start(context.Background(), "recorded_later", ft.WithStartTime(start))
+	span.End()
+
+	assert.Contains(t, buf.String(), "action=recorded_later duration_ms=2000")
+}
+
+func TestSpan_PanicErrorSetByCaller(t *testing.T) {
+	t.Parallel()
+
+	var logBuffer testLogBuffer
+	tracer := ft.New(
+		ft.WithLogger(slog.New(slog.NewTextHandler(&logBuffer, nil))),
+		ft.WithTraceLogFields(ft.TraceLogFields{}),
+		ft.WithClock(clockwork.NewFakeClock()),
+	)
+
+	require.NotPanics(t, func() {
+		_, span := tracer.Start(context.Background(), "test_recovered_elsewhere")
+		span.SetError(&ft.PanicError{Value: "boom"})
+		span.End()
+	})
+
+	assert.Contains(t, logBuffer.String(), `level=ERROR msg="action ended" action=test_recovered_elsewhere duration_ms=0 panic=true error="panic: boom"`)
+}
+
 func findHistogramMetric(rm metricdata.ResourceMetrics, name string) (metricdata.Histogram[float64], bool) {
 	for _, scopeMetrics := range rm.ScopeMetrics {
 		for _, metric := range scopeMetrics.Metrics {
@@ -401,6 +1675,20 @@ func findHistogramMetric(rm metricdata.ResourceMetrics, name string) (metricdata
 	return metricdata.Histogram[float64]{}, false
 }
 
+func findSumMetric(rm metricdata.ResourceMetrics, name string) (metricdata.Sum[int64], bool) {
+	for _, scopeMetrics := range rm.ScopeMetrics {
+		for _, metric := range scopeMetrics.Metrics {
+			if metric.Name != name {
+				continue
+			}
+			sum, ok := metric.Data.(metricdata.Sum[int64])
+			return sum, ok
+		}
+	}
+
+	return metricdata.Sum[int64]{}, false
+}
+
 type testLogBuffer struct {
 	content string
 }
@@ -417,3 +1705,35 @@ func (b *testLogBuffer) String() string {
 func (b *testLogBuffer) Reset() {
 	b.content = ""
 }
+
+// testLogExporter is an in-memory sdklog.Exporter.
+type testLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *testLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+
+	return nil
+}
+
+func (e *testLogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *testLogExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *testLogExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.records)
+}
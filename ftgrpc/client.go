@@ -0,0 +1,128 @@
+package ftgrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor returns an interceptor that wraps every unary call in an ft span named after the full method
+// and injects the trace context into the outgoing metadata.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) (err error) {
+		c := cfg.start(ctx, method, trace.SpanKindClient)
+		defer func() {
+			if r := recover(); r != nil {
+				c.endPanic(r)
+			}
+			c.end(err)
+		}()
+
+		return invoker(inject(c.ctx, cfg), method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that wraps every streaming call in an ft span named after the full method
+// and injects the trace context into the outgoing metadata.
+//
+// The span ends when the stream has been received to the end or fails,
+// so the caller must read the stream until RecvMsg returns an error.
+// The numbers of sent and received messages are added to the span when it ends.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := cfg.start(ctx, method, trace.SpanKindClient)
+		defer func() {
+			if r := recover(); r != nil {
+				c.endPanic(r)
+			}
+		}()
+
+		cs, err := streamer(inject(c.ctx, cfg), desc, cc, method, callOpts...)
+		if err != nil {
+			c.end(err)
+			return nil, err
+		}
+
+		return &clientStream{ClientStream: cs, call: c, serverStreams: desc.ServerStreams}, nil
+	}
+}
+
+func inject(ctx context.Context, cfg *config) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	cfg.propagator.Inject(ctx, metadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// clientStream counts the messages and ends the span when the stream is finished.
+type clientStream struct {
+	grpc.ClientStream
+	call          *call
+	serverStreams bool
+	mu            sync.Mutex
+	sent          int64
+	received      int64
+	once          sync.Once
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.sent++
+		s.mu.Unlock()
+	} else if !errors.Is(err, io.EOF) {
+		s.end(err)
+	}
+
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+		s.mu.Lock()
+		s.received++
+		s.mu.Unlock()
+
+		if !s.serverStreams {
+			s.end(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+
+	return err
+}
+
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		sent, received := s.sent, s.received
+		s.mu.Unlock()
+
+		s.call.end(err,
+			slog.Int64(messagesSentKey, sent),
+			slog.Int64(messagesReceivedKey, received),
+		)
+	})
+}
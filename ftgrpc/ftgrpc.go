@@ -0,0 +1,200 @@
+// Package ftgrpc instruments gRPC servers and clients with ft spans.
+//
+// It lives in a separate module so that the core ft module does not depend on gRPC.
+package ftgrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+
+	"github.com/amanbolat/ft"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	messagesSentKey     = "rpc.messages.sent"
+	messagesReceivedKey = "rpc.messages.received"
+)
+
+type config struct {
+	tracer        *ft.Tracer
+	propagator    propagation.TextMapPropagator
+	expectedCodes map[codes.Code]struct{}
+	spanOpts      []ft.Option
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		propagator:    propagation.TraceContext{},
+		expectedCodes: map[codes.Code]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// Option configures the interceptors.
+type Option func(cfg *config)
+
+// WithTracer sets the Tracer used to start spans. By default, the default ft Tracer is used.
+func WithTracer(t *ft.Tracer) Option {
+	return func(cfg *config) {
+		cfg.tracer = t
+	}
+}
+
+// WithPropagator sets the propagator used to extract and inject the trace context.
+// Defaults to the W3C Trace Context propagator.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		if p != nil {
+			cfg.propagator = p
+		}
+	}
+}
+
+// WithExpectedCodes sets the status codes that are expected outcomes of a call, e.g. codes.NotFound.
+// Calls ending with these codes are logged at the success level and do not mark the span as failed.
+func WithExpectedCodes(c ...codes.Code) Option {
+	return func(cfg *config) {
+		for _, code := range c {
+			cfg.expectedCodes[code] = struct{}{}
+		}
+	}
+}
+
+// WithSpanOptions adds options to every span started by the interceptors.
+func WithSpanOptions(opts ...ft.Option) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = append(cfg.spanOpts, opts...)
+	}
+}
+
+// call is a single instrumented RPC.
+type call struct {
+	cfg       *config
+	parentCtx context.Context
+	ctx       context.Context
+	span      ft.Span
+	err       error
+}
+
+func (cfg *config) start(ctx context.Context, fullMethod string, kind trace.SpanKind) *call {
+	tracer := cfg.tracer
+	if tracer == nil {
+		tracer = ft.Default()
+	}
+
+	service, method := splitFullMethod(fullMethod)
+	c := &call{cfg: cfg, parentCtx: ctx}
+
+	spanOpts := make([]ft.Option, 0, len(cfg.spanOpts)+3)
+	spanOpts = append(spanOpts, cfg.spanOpts...)
+	spanOpts = append(spanOpts,
+		ft.WithErr(&c.err),
+		ft.WithSpanKind(kind),
+		ft.WithAttrs(
+			slog.String(string(semconv.RPCSystemKey), "grpc"),
+			slog.String(string(semconv.RPCServiceKey), service),
+			slog.String(string(semconv.RPCMethodKey), method),
+		),
+	)
+
+	c.ctx, c.span = tracer.Start(ctx, fullMethod, spanOpts...)
+
+	if s := c.otelSpan(); s != nil {
+		s.SetAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+
+	return c
+}
+
+// otelSpan returns the OpenTelemetry span started by ft, or nil if ft has not started one,
+// so that attributes are never set on the span of the caller.
+func (c *call) otelSpan() trace.Span {
+	s := trace.SpanFromContext(c.ctx)
+	if s.SpanContext().SpanID() == trace.SpanContextFromContext(c.parentCtx).SpanID() {
+		return nil
+	}
+
+	return s
+}
+
+// end ends the span with the status of err. Errors with expected codes are recorded only as the status code,
+// except for panics.
+func (c *call) end(err error, attrs ...slog.Attr) {
+	code := status.Code(err)
+
+	var panicErr *ft.PanicError
+	if _, ok := c.cfg.expectedCodes[code]; !ok || errors.As(err, &panicErr) {
+		c.err = err
+	}
+
+	attrs = append(attrs, slog.Int(string(semconv.RPCGRPCStatusCodeKey), int(code)))
+	c.span.AddAttrs(attrs...)
+
+	if s := c.otelSpan(); s != nil {
+		kvs := make([]attribute.KeyValue, 0, len(attrs))
+		for _, attr := range attrs {
+			kvs = append(kvs, attribute.Int64(attr.Key, attr.Value.Int64()))
+		}
+		s.SetAttributes(kvs...)
+	}
+
+	c.span.End()
+}
+
+// endPanic ends the span with the recovered value r as a *ft.PanicError and panics again with r.
+// The span can't recover the panic itself because End is not deferred directly by the interceptors,
+// but it records the *ft.PanicError as a panic.
+func (c *call) endPanic(r any, attrs ...slog.Attr) {
+	c.end(&ft.PanicError{Value: r, Stack: debug.Stack()}, attrs...)
+	panic(r)
+}
+
+// splitFullMethod splits "/package.Service/Method" into the service and the method.
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "", fullMethod
+	}
+
+	return service, method
+}
+
+// metadataCarrier adapts metadata.MD to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,160 @@
+package ftgrpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amanbolat/ft"
+	"github.com/amanbolat/ft/ftgrpc"
+	"github.com/amanbolat/ft/fttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	checkMethod = "/grpc.health.v1.Health/Check"
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+func newClient(t *testing.T, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) healthpb.HealthClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("svc", healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer(serverOpts...)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	t.Parallel()
+
+	serverRec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	clientRec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	client := newClient(t,
+		[]grpc.ServerOption{grpc.UnaryInterceptor(ftgrpc.UnaryServerInterceptor(
+			ftgrpc.WithTracer(serverRec.Tracer()),
+			ftgrpc.WithExpectedCodes(codes.NotFound),
+		))},
+		grpc.WithUnaryInterceptor(ftgrpc.UnaryClientInterceptor(ftgrpc.WithTracer(clientRec.Tracer()))),
+	)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "svc"})
+	require.NoError(t, err)
+
+	serverCall := serverRec.Ended(checkMethod)
+	require.NoError(t, serverCall.Err())
+	assert.Equal(t, "grpc.health.v1.Health", serverRec.Attr(checkMethod, "rpc.service").String())
+	assert.Equal(t, "Check", serverRec.Attr(checkMethod, "rpc.method").String())
+	assert.Equal(t, int64(codes.OK), serverRec.Attr(checkMethod, "rpc.grpc.status_code").Int64())
+	require.NoError(t, clientRec.Ended(checkMethod).Err())
+
+	serverSpan := serverRec.Spans(checkMethod)[0]
+	clientSpan := clientRec.Spans(checkMethod)[0]
+	assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind())
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind())
+	assert.Equal(t, clientSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.Contains(t, serverSpan.Attributes(), attribute.String("rpc.system", "grpc"))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	serverCall = serverRec.Ended(checkMethod)
+	require.NoError(t, serverCall.Err(), "expected codes must not fail the span")
+	assert.Equal(t, slog.LevelInfo, serverCall.Level())
+	assert.Equal(t, int64(codes.NotFound), serverRec.Attr(checkMethod, "rpc.grpc.status_code").Int64())
+
+	clientCall := clientRec.Ended(checkMethod)
+	assert.Equal(t, codes.NotFound, status.Code(clientCall.Err()))
+	assert.Equal(t, slog.LevelError, clientCall.Level())
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	t.Parallel()
+
+	serverRec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	clientRec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	client := newClient(t,
+		[]grpc.ServerOption{grpc.StreamInterceptor(ftgrpc.StreamServerInterceptor(ftgrpc.WithTracer(serverRec.Tracer())))},
+		grpc.WithStreamInterceptor(ftgrpc.StreamClientInterceptor(ftgrpc.WithTracer(clientRec.Tracer()))),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "svc"})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	cancel()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	require.False(t, errors.Is(err, io.EOF))
+	assert.Equal(t, codes.Canceled, status.Code(clientRec.Ended(watchMethod).Err()))
+	assert.Equal(t, int64(1), clientRec.Attr(watchMethod, "rpc.messages.sent").Int64())
+	assert.Equal(t, int64(1), clientRec.Attr(watchMethod, "rpc.messages.received").Int64())
+
+	require.Eventually(t, func() bool { return len(serverRec.Calls(watchMethod)) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), serverRec.Attr(watchMethod, "rpc.messages.sent").Int64())
+	assert.Equal(t, int64(1), serverRec.Attr(watchMethod, "rpc.messages.received").Int64())
+}
+
+func TestUnaryServerInterceptor_Panic(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	interceptor := ftgrpc.UnaryServerInterceptor(ftgrpc.WithTracer(rec.Tracer()), ftgrpc.WithExpectedCodes(codes.Unknown))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: checkMethod},
+			func(context.Context, any) (any, error) { panic("boom") })
+	})
+
+	var panicErr *ft.PanicError
+	require.ErrorAs(t, rec.Ended(checkMethod).Err(), &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.Equal(t, int64(codes.Unknown), rec.Attr(checkMethod, "rpc.grpc.status_code").Int64())
+	assert.True(t, rec.Attr(checkMethod, "panic").Bool())
+
+	var found bool
+	for _, scopeMetrics := range rec.Metrics().ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			found = found || strings.HasSuffix(m.Name, "_panic_counter")
+		}
+	}
+	assert.True(t, found, "expected the panic counter to be recorded")
+}
@@ -0,0 +1,91 @@
+package ftgrpc
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor returns an interceptor that wraps every unary call in an ft span named after the full method.
+// The span continues the trace of the caller if the call metadata carries trace context.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		c := cfg.start(extract(ctx, cfg), info.FullMethod, trace.SpanKindServer)
+		defer func() {
+			if r := recover(); r != nil {
+				c.endPanic(r)
+			}
+			c.end(err)
+		}()
+
+		return handler(c.ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that wraps every streaming call in an ft span named after the full method.
+// The numbers of sent and received messages are added to the span when the call ends.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		c := cfg.start(extract(ss.Context(), cfg), info.FullMethod, trace.SpanKindServer)
+
+		stream := &serverStream{ServerStream: ss, ctx: c.ctx}
+		defer func() {
+			attrs := []slog.Attr{
+				slog.Int64(messagesSentKey, stream.sent),
+				slog.Int64(messagesReceivedKey, stream.received),
+			}
+			if r := recover(); r != nil {
+				c.endPanic(r, attrs...)
+			}
+			c.end(err, attrs...)
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func extract(ctx context.Context, cfg *config) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return cfg.propagator.Extract(ctx, metadataCarrier(md))
+}
+
+// serverStream overrides the context of the stream and counts the messages.
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     int64
+	received int64
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+
+	return err
+}
@@ -0,0 +1,168 @@
+package fthttp
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/amanbolat/ft"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport wraps the base RoundTripper with an ft span per request. If base is nil, http.DefaultTransport is used.
+//
+// The action is named after the method and the host, e.g. "GET api.example.com".
+// The trace context is injected into the request headers.
+// The span ends when the response body is read to the end or closed, and records the status code
+// and the number of bytes read. The span of a 101 Switching Protocols response ends when it is returned,
+// and its body is left unwrapped. Transport errors and, by default, 5xx responses are treated as failures.
+func Transport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{base: base, cfg: newConfig(opts)}
+}
+
+type transport struct {
+	base http.RoundTripper
+	cfg  *config
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	action := r.Method + " " + r.URL.Host
+	if t.cfg.actionNamer != nil {
+		action = t.cfg.actionNamer(r)
+	}
+
+	attrs := []slog.Attr{
+		slog.String(string(semconv.HTTPRequestMethodKey), r.Method),
+		slog.String(string(semconv.ServerAddressKey), r.URL.Hostname()),
+	}
+
+	parentCtx := r.Context()
+	var err error
+	ctx, span := t.cfg.start(parentCtx, action, ft.WithErr(&err), ft.WithAttrs(attrs...), ft.WithSpanKind(trace.SpanKindClient))
+
+	r = r.Clone(ctx)
+	t.cfg.propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, rtErr := t.roundTrip(r, span)
+	if rtErr != nil {
+		err = rtErr
+		span.End()
+
+		return nil, rtErr
+	}
+
+	span.AddAttrs(slog.Int(string(semconv.HTTPResponseStatusCodeKey), resp.StatusCode))
+
+	otelSpan := ownSpan(parentCtx, ctx)
+	if otelSpan != nil {
+		otelSpan.SetAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.ServerAddress(r.URL.Hostname()),
+			semconv.HTTPResponseStatusCode(resp.StatusCode),
+		)
+	}
+
+	if t.cfg.isFailure(resp.StatusCode) {
+		err = &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body := &trackedBody{
+		span:     span,
+		otelSpan: otelSpan,
+		err:      &err,
+	}
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+		body.end(nil)
+		return resp, nil
+	}
+
+	// The body of a 101 Switching Protocols response is the connection, e.g. of a WebSocket,
+	// and implements io.ReadWriteCloser. It is passed on as is and the span ends with the handshake.
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		span.End()
+		return resp, nil
+	}
+
+	body.ReadCloser = resp.Body
+	resp.Body = body
+
+	return resp, nil
+}
+
+// roundTrip calls the base RoundTripper. If it panics, the span ends with a *ft.PanicError before the panic continues.
+func (t *transport) roundTrip(r *http.Request, span ft.Span) (*http.Response, error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			span.SetError(&ft.PanicError{Value: recovered, Stack: debug.Stack()})
+			span.End()
+			panic(recovered)
+		}
+	}()
+
+	return t.base.RoundTrip(r)
+}
+
+// trackedBody counts the bytes read from the response body and ends the span
+// when the body is read to the end, fails to be read or is closed.
+type trackedBody struct {
+	io.ReadCloser
+	span     ft.Span
+	otelSpan trace.Span
+	err      *error
+	size     int64
+	mu       sync.Mutex
+	once     sync.Once
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	b.size += int64(n)
+	b.mu.Unlock()
+
+	switch {
+	case errors.Is(err, io.EOF):
+		b.end(nil)
+	case err != nil:
+		b.end(err)
+	}
+
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end(nil)
+
+	return err
+}
+
+func (b *trackedBody) end(readErr error) {
+	b.once.Do(func() {
+		b.mu.Lock()
+		size := b.size
+		b.mu.Unlock()
+
+		if readErr != nil && *b.err == nil {
+			*b.err = readErr
+		}
+
+		b.span.AddAttrs(slog.Int64(string(semconv.HTTPResponseBodySizeKey), size))
+		if b.otelSpan != nil {
+			b.otelSpan.SetAttributes(semconv.HTTPResponseBodySizeKey.Int64(size))
+		}
+
+		b.span.End()
+	})
+}
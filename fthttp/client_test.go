@@ -0,0 +1,170 @@
+package fthttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/amanbolat/ft"
+	"github.com/amanbolat/ft/fthttp"
+	"github.com/amanbolat/ft/fttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	action := "GET " + serverURL.Host
+
+	client := &http.Client{Transport: fthttp.Transport(nil, fthttp.WithTracer(rec.Tracer()))}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	assert.Empty(t, rec.Calls(action), "span must not end before the body is read")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "hello", string(body))
+
+	require.Len(t, rec.Calls(action), 1)
+	call := rec.Ended(action)
+	require.NoError(t, call.Err())
+	assert.Equal(t, int64(http.StatusOK), rec.Attr(action, "http.response.status_code").Int64())
+	assert.Equal(t, int64(len("hello")), rec.Attr(action, "http.response.body.size").Int64())
+
+	spans := rec.Spans(action)
+	require.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Contains(t, traceparent, spans[0].SpanContext().SpanID().String())
+
+	resp, err = client.Get(server.URL + "/fail")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	var statusErr *fthttp.StatusError
+	require.ErrorAs(t, rec.Ended(action).Err(), &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Equal(t, slog.LevelError, rec.Ended(action).Level())
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransport_Error(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	testErr := errors.New("connection refused")
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, testErr
+	})
+
+	client := &http.Client{Transport: fthttp.Transport(base,
+		fthttp.WithTracer(rec.Tracer()),
+		fthttp.WithActionNamer(func(r *http.Request) string { return "users.Get" }),
+	)}
+
+	_, err := client.Get("http://users.internal/users/1")
+	require.ErrorIs(t, err, testErr)
+	require.ErrorIs(t, rec.Ended("users.Get").Err(), testErr)
+	assert.Equal(t, "users.internal", rec.Attr("users.Get", "server.address").String())
+}
+
+func TestTransport_Panic(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		panic("boom")
+	})
+	transport := fthttp.Transport(base,
+		fthttp.WithTracer(rec.Tracer()),
+		fthttp.WithActionNamer(func(r *http.Request) string { return "users.Get" }),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "http://users.internal/users/1", nil)
+	assert.PanicsWithValue(t, "boom", func() { _, _ = transport.RoundTrip(req) })
+
+	var panicErr *ft.PanicError
+	require.ErrorAs(t, rec.Ended("users.Get").Err(), &panicErr)
+	assert.True(t, rec.Attr("users.Get", "panic").Bool())
+}
+
+func TestTransport_SwitchingProtocols(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Upgrade", "echo")
+		w.WriteHeader(http.StatusSwitchingProtocols)
+
+		conn, brw, err := http.NewResponseController(w).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := brw.ReadString('\n')
+		_, _ = brw.WriteString(line)
+		_ = brw.Flush()
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: fthttp.Transport(nil,
+		fthttp.WithTracer(rec.Tracer()),
+		fthttp.WithActionNamer(func(r *http.Request) string { return "upgrade" }),
+	)}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "echo")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	require.True(t, ok, "the body of a 101 response must stay writable")
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = io.WriteString(conn, "ping\n")
+	require.NoError(t, err)
+	reply := make([]byte, len("ping\n"))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "ping\n", string(reply))
+
+	require.NoError(t, rec.Ended("upgrade").Err())
+	assert.Equal(t, int64(http.StatusSwitchingProtocols), rec.Attr("upgrade", "http.response.status_code").Int64())
+}
@@ -0,0 +1,120 @@
+// Package fthttp instruments net/http servers and clients with ft spans.
+package fthttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/amanbolat/ft"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StatusError is the error a span ends with when the response status code is treated as a failure.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type config struct {
+	tracer      *ft.Tracer
+	propagator  propagation.TextMapPropagator
+	actionNamer func(r *http.Request) string
+	isFailure   func(statusCode int) bool
+	spanOpts    []ft.Option
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		propagator: propagation.TraceContext{},
+		isFailure:  statusClasses(5),
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+func (cfg *config) start(ctx context.Context, action string, opts ...ft.Option) (context.Context, ft.Span) {
+	tracer := cfg.tracer
+	if tracer == nil {
+		tracer = ft.Default()
+	}
+
+	spanOpts := make([]ft.Option, 0, len(cfg.spanOpts)+len(opts))
+	spanOpts = append(spanOpts, cfg.spanOpts...)
+	spanOpts = append(spanOpts, opts...)
+
+	return tracer.Start(ctx, action, spanOpts...)
+}
+
+// Option configures the server middleware and the client transport.
+type Option func(cfg *config)
+
+// WithTracer sets the Tracer used to start spans. By default, the default ft Tracer is used.
+func WithTracer(t *ft.Tracer) Option {
+	return func(cfg *config) {
+		cfg.tracer = t
+	}
+}
+
+// WithPropagator sets the propagator used to extract and inject the trace context.
+// Defaults to the W3C Trace Context propagator.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		if p != nil {
+			cfg.propagator = p
+		}
+	}
+}
+
+// WithActionNamer sets the function that names the action of a request.
+func WithActionNamer(fn func(r *http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.actionNamer = fn
+	}
+}
+
+// WithFailureStatusClasses sets the classes of status codes treated as failures, e.g. 5 for 5xx.
+// Defaults to 5xx.
+func WithFailureStatusClasses(classes ...int) Option {
+	return func(cfg *config) {
+		cfg.isFailure = statusClasses(classes...)
+	}
+}
+
+// WithSpanOptions adds options to every span started by the middleware or transport.
+func WithSpanOptions(opts ...ft.Option) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = append(cfg.spanOpts, opts...)
+	}
+}
+
+func statusClasses(classes ...int) func(statusCode int) bool {
+	return func(statusCode int) bool {
+		for _, class := range classes {
+			if statusCode/100 == class {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ownSpan returns the OpenTelemetry span started by ft, or nil if ft has not started one,
+// so that attributes are never set on the span of the caller.
+func ownSpan(parentCtx, ctx context.Context) trace.Span {
+	s := trace.SpanFromContext(ctx)
+	if s.SpanContext().SpanID() == trace.SpanContextFromContext(parentCtx).SpanID() {
+		return nil
+	}
+
+	return s
+}
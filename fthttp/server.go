@@ -0,0 +1,172 @@
+package fthttp
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/amanbolat/ft"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// hijackedKey marks the spans of requests whose connection was hijacked by the handler.
+const hijackedKey = "http.hijacked"
+
+// Middleware wraps the handler with an ft span per request.
+//
+// The action is named after the route pattern of the request, e.g. "GET /users/{id}".
+// If next is an *http.ServeMux, the pattern is resolved before the request is routed.
+// The span continues the trace of the caller if the request carries trace context headers.
+// Method, route, status code and response size are recorded as attributes,
+// and responses with a 5xx status code end the span with a *StatusError.
+// If the handler hijacks the connection, the status code and the size are unknown,
+// so the span gets http.hijacked=true instead and ends successfully.
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	cfg := newConfig(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parentCtx := cfg.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		r = r.WithContext(parentCtx)
+
+		if r.Pattern == "" {
+			if mux, ok := next.(*http.ServeMux); ok {
+				_, r.Pattern = mux.Handler(r)
+			}
+		}
+
+		action := serverActionName(r)
+		if cfg.actionNamer != nil {
+			action = cfg.actionNamer(r)
+		}
+
+		route := routeOf(r.Pattern)
+		attrs := []slog.Attr{slog.String(string(semconv.HTTPRequestMethodKey), r.Method)}
+		if route != "" {
+			attrs = append(attrs, slog.String(string(semconv.HTTPRouteKey), route))
+		}
+
+		var err error
+		ctx, span := cfg.start(parentCtx, action, ft.WithErr(&err), ft.WithAttrs(attrs...), ft.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		otelSpan := ownSpan(parentCtx, ctx)
+		if otelSpan != nil {
+			otelSpan.SetAttributes(semconv.HTTPRequestMethodKey.String(r.Method))
+			if route != "" {
+				otelSpan.SetAttributes(semconv.HTTPRoute(route))
+			}
+		}
+
+		// The status code and the size of a response written to a hijacked connection are unknown.
+		if rw.hijacked {
+			span.AddAttrs(slog.Bool(hijackedKey, true))
+			if otelSpan != nil {
+				otelSpan.SetAttributes(attribute.Bool(hijackedKey, true))
+			}
+
+			return
+		}
+
+		span.AddAttrs(
+			slog.Int(string(semconv.HTTPResponseStatusCodeKey), rw.statusCode),
+			slog.Int64(string(semconv.HTTPResponseBodySizeKey), rw.size),
+		)
+
+		if otelSpan != nil {
+			otelSpan.SetAttributes(
+				semconv.HTTPResponseStatusCode(rw.statusCode),
+				semconv.HTTPResponseBodySizeKey.Int64(rw.size),
+			)
+		}
+
+		if cfg.isFailure(rw.statusCode) {
+			err = &StatusError{StatusCode: rw.statusCode}
+		}
+	})
+}
+
+// serverActionName returns the route pattern prefixed with the method, or only the method if the pattern is unknown.
+func serverActionName(r *http.Request) string {
+	if r.Pattern == "" {
+		return r.Method
+	}
+
+	if routeOf(r.Pattern) != r.Pattern {
+		return r.Pattern
+	}
+
+	return r.Method + " " + r.Pattern
+}
+
+// routeOf strips the method from the route pattern, e.g. "GET /users/{id}" becomes "/users/{id}".
+func routeOf(pattern string) string {
+	if method, route, ok := strings.Cut(pattern, " "); ok && method != "" && !strings.Contains(method, "/") {
+		return strings.TrimLeft(route, " \t")
+	}
+
+	return pattern
+}
+
+// responseWriter records the status code and the size of the response.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	size        int64
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader && statusCode >= http.StatusOK {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher for handlers that stream responses.
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for handlers that take over the connection, e.g. for WebSockets.
+// It returns an error wrapping http.ErrNotSupported if the original http.ResponseWriter can't be hijacked.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+
+	return conn, brw, err
+}
+
+// Push implements http.Pusher. It returns http.ErrNotSupported if the original http.ResponseWriter doesn't support push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the original http.ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
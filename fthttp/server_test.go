@@ -0,0 +1,150 @@
+package fthttp_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amanbolat/ft/fthttp"
+	"github.com/amanbolat/ft/fttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "user "+r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	handler := fthttp.Middleware(mux, fthttp.WithTracer(rec.Tracer()))
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, "user 42", resp.Body.String())
+
+	call := rec.Ended("GET /users/{id}")
+	require.NoError(t, call.Err())
+	assert.Equal(t, slog.LevelInfo, call.Level())
+	assert.Equal(t, "GET", rec.Attr("GET /users/{id}", "http.request.method").String())
+	assert.Equal(t, "/users/{id}", rec.Attr("GET /users/{id}", "http.route").String())
+	assert.Equal(t, int64(http.StatusOK), rec.Attr("GET /users/{id}", "http.response.status_code").Int64())
+	assert.Equal(t, int64(len("user 42")), rec.Attr("GET /users/{id}", "http.response.body.size").Int64())
+
+	spans := rec.Spans("GET /users/{id}")
+	require.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("http.route", "/users/{id}"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	call = rec.Ended("POST /users")
+	var statusErr *fthttp.StatusError
+	require.ErrorAs(t, call.Err(), &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, slog.LevelError, call.Level())
+	assert.Equal(t, codes.Error, rec.Spans("POST /users")[0].Status().Code)
+}
+
+func TestMiddleware_ContinuesTrace(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	var handlerSpanContext trace.SpanContext
+	handler := fthttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpanContext = trace.SpanContextFromContext(r.Context())
+	}), fthttp.WithTracer(rec.Tracer()))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Spans("GET")
+	require.Len(t, spans, 1)
+	assert.Equal(t, traceID, spans[0].SpanContext().TraceID())
+	assert.Equal(t, spanID, spans[0].Parent().SpanID())
+	assert.True(t, spans[0].Parent().IsRemote())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpanContext.SpanID())
+}
+
+func TestMiddleware_Options(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	handler := fthttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}),
+		fthttp.WithTracer(rec.Tracer()),
+		fthttp.WithPropagator(propagation.NewCompositeTextMapPropagator()),
+		fthttp.WithFailureStatusClasses(4, 5),
+		fthttp.WithActionNamer(func(r *http.Request) string { return "http." + r.Method }),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+
+	var statusErr *fthttp.StatusError
+	require.ErrorAs(t, rec.Ended("http.DELETE").Err(), &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestMiddleware_Hijack(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+
+	handler := fthttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, ok := w.(http.Pusher)
+		assert.True(t, ok)
+
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = brw.WriteString("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+		_ = brw.Flush()
+	}), fthttp.WithTracer(rec.Tracer()), fthttp.WithActionNamer(func(*http.Request) string { return "hijack" }))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	call := rec.Ended("hijack")
+	require.NoError(t, call.Err())
+	assert.True(t, rec.Attr("hijack", "http.hijacked").Bool())
+	_, ok := call.Attr("http.response.status_code")
+	assert.False(t, ok, "the status code of a hijacked connection is unknown")
+
+	fthttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _, err := w.(http.Hijacker).Hijack()
+		require.ErrorIs(t, err, http.ErrNotSupported)
+		require.ErrorIs(t, w.(http.Pusher).Push("/style.css", nil), http.ErrNotSupported)
+	}), fthttp.WithTracer(rec.Tracer())).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
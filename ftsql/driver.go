@@ -0,0 +1,418 @@
+package ftsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/amanbolat/ft"
+)
+
+type wrappedDriver struct {
+	driver.Driver
+	cfg *config
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedConn{Conn: c, cfg: d.cfg}, nil
+}
+
+type wrappedConnector struct {
+	driver.Connector
+	driver *wrappedDriver
+	cfg    *config
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedConn{Conn: conn, cfg: c.cfg}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is a connector for drivers that do not implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// wrappedConn implements the optional interfaces of driver.Conn by delegating to the wrapped connection
+// and falling back to the behavior database/sql expects when the wrapped connection does not implement them.
+type wrappedConn struct {
+	driver.Conn
+	cfg *config
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	o := c.cfg.start(ctx, OpPrepare, query)
+
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(o.ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	o.end(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newWrappedStmt(stmt, c.Conn, query, c.cfg), nil
+}
+
+// ExecContext records the span only after the wrapped connection returned, because drivers may return driver.ErrSkip,
+// e.g. for every statement with arguments, and database/sql retries with a prepared statement that has its own spans.
+// Therefore, the context passed to the wrapped connection does not carry the span.
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := c.cfg.getTracer().Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+
+	o := c.cfg.start(ctx, OpExec, query, ft.WithStartTime(start))
+	o.endExec(res, err)
+
+	return res, err
+}
+
+// QueryContext records the span only after the wrapped connection returned, see ExecContext.
+// The span ends when the rows are closed.
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := c.cfg.getTracer().Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+
+	o := c.cfg.start(ctx, OpQuery, query, ft.WithStartTime(start))
+
+	return o.wrapRows(rows, err)
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	o := c.cfg.start(ctx, OpBegin, "")
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = bc.BeginTx(o.ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin() //nolint:staticcheck // fallback for drivers without ConnBeginTx.
+	}
+	o.end(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedTx{Tx: tx, ctx: ctx, cfg: c.cfg}, nil
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type wrappedStmt struct {
+	driver.Stmt
+	conn  driver.Conn
+	query string
+	cfg   *config
+}
+
+// wrappedStmtColumnConverter is used for statements implementing driver.ColumnConverter,
+// because database/sql prefers the converter over the default conversion as soon as a statement implements it.
+type wrappedStmtColumnConverter struct {
+	*wrappedStmt
+}
+
+func (s wrappedStmtColumnConverter) ColumnConverter(idx int) driver.ValueConverter {
+	return s.Stmt.(driver.ColumnConverter).ColumnConverter(idx) //nolint:staticcheck // forwarded for drivers that still use it.
+}
+
+func newWrappedStmt(stmt driver.Stmt, conn driver.Conn, query string, cfg *config) driver.Stmt {
+	s := &wrappedStmt{Stmt: stmt, conn: conn, query: query, cfg: cfg}
+	if _, ok := stmt.(driver.ColumnConverter); ok { //nolint:staticcheck // forwarded for drivers that still use it.
+		return wrappedStmtColumnConverter{wrappedStmt: s}
+	}
+
+	return s
+}
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	o := s.cfg.start(ctx, OpExec, s.query)
+
+	var (
+		res driver.Result
+		err error
+	)
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(o.ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			res, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback for statements without StmtExecContext.
+		}
+	}
+	o.endExec(res, err)
+
+	return res, err
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+// QueryContext starts a span that ends when the rows are closed.
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	o := s.cfg.start(ctx, OpQuery, s.query)
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(o.ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // fallback for statements without StmtQueryContext.
+		}
+	}
+
+	return o.wrapRows(rows, err)
+}
+
+// CheckNamedValue delegates to the statement or, like database/sql does for unwrapped statements, to the connection.
+func (s *wrappedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// wrappedTx keeps the context of BeginTx, because driver.Tx methods do not accept one.
+type wrappedTx struct {
+	driver.Tx
+	ctx context.Context
+	cfg *config
+}
+
+func (t *wrappedTx) Commit() error {
+	o := t.cfg.start(t.ctx, OpCommit, "")
+	err := t.Tx.Commit()
+	o.end(err)
+
+	return err
+}
+
+func (t *wrappedTx) Rollback() error {
+	o := t.cfg.start(t.ctx, OpRollback, "")
+	err := t.Tx.Rollback()
+	o.end(err)
+
+	return err
+}
+
+// wrapRows ends the span if the query failed, otherwise it returns rows that end the span when they are closed.
+func (o *operation) wrapRows(rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		o.end(err)
+		return nil, err
+	}
+
+	return &wrappedRows{Rows: rows, op: o}, nil
+}
+
+// wrappedRows records the errors of Next and ends the span of the query on Close.
+// Like wrappedConn, it implements the optional interfaces of driver.Rows with the fallbacks of database/sql.
+type wrappedRows struct {
+	driver.Rows
+	op  *operation
+	err error
+}
+
+func (r *wrappedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+
+	return err
+}
+
+func (r *wrappedRows) Close() error {
+	err := r.Rows.Close()
+
+	if r.err != nil {
+		r.op.end(r.err)
+	} else {
+		r.op.end(err)
+	}
+
+	return err
+}
+
+func (r *wrappedRows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+
+	return false
+}
+
+func (r *wrappedRows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		err := rs.NextResultSet()
+		if err != nil && !errors.Is(err, io.EOF) {
+			r.err = err
+		}
+
+		return err
+	}
+
+	return io.EOF
+}
+
+func (r *wrappedRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+
+	return reflect.TypeFor[any]()
+}
+
+func (r *wrappedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+
+	return ""
+}
+
+func (r *wrappedRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+
+	return 0, false
+}
+
+func (r *wrappedRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+
+	return false, false
+}
+
+func (r *wrappedRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+
+	return 0, 0, false
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return named
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("ftsql: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
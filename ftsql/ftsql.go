@@ -0,0 +1,170 @@
+// Package ftsql wraps database/sql drivers to emit an ft span for every
+// Exec, Query, Prepare, Begin, Commit and Rollback.
+package ftsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+
+	"github.com/amanbolat/ft"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Op is a database operation instrumented with a span.
+type Op string
+
+const (
+	OpExec     Op = "exec"
+	OpQuery    Op = "query"
+	OpPrepare  Op = "prepare"
+	OpBegin    Op = "begin"
+	OpCommit   Op = "commit"
+	OpRollback Op = "rollback"
+)
+
+const (
+	statementAttrKey    = "db.statement"
+	rowsAffectedAttrKey = "db.rows_affected"
+)
+
+type config struct {
+	tracer      *ft.Tracer
+	actionNamer func(op Op, query string) string
+	sanitizer   func(query string) string
+	spanOpts    []ft.Option
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		actionNamer: func(op Op, _ string) string { return "db." + string(op) },
+		sanitizer:   SanitizeStatement,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// Option configures the wrapped driver.
+type Option func(cfg *config)
+
+// WithTracer sets the Tracer used to start spans. By default, the default ft Tracer is used.
+func WithTracer(t *ft.Tracer) Option {
+	return func(cfg *config) {
+		cfg.tracer = t
+	}
+}
+
+// WithActionNamer sets the function that names the action of an operation.
+// By default, actions are named "db.<op>", e.g. "db.query".
+func WithActionNamer(fn func(op Op, query string) string) Option {
+	return func(cfg *config) {
+		if fn != nil {
+			cfg.actionNamer = fn
+		}
+	}
+}
+
+// WithStatementSanitizer sets the function applied to SQL statements before they are attached to spans.
+// Defaults to SanitizeStatement. Passing nil attaches statements as is.
+func WithStatementSanitizer(fn func(query string) string) Option {
+	return func(cfg *config) {
+		cfg.sanitizer = fn
+	}
+}
+
+// WithSpanOptions adds options to every span started by the driver.
+func WithSpanOptions(opts ...ft.Option) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = append(cfg.spanOpts, opts...)
+	}
+}
+
+// Open opens a database using the registered driver wrapped with Wrap.
+func Open(driverName, dsn string, opts ...Option) (*sql.DB, error) {
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	_ = db.Close()
+
+	if dc, ok := d.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		return sql.OpenDB(WrapConnector(connector, opts...)), nil
+	}
+
+	return sql.OpenDB(&dsnConnector{dsn: dsn, driver: Wrap(d, opts...)}), nil
+}
+
+// Wrap returns a driver that emits an ft span for every operation of the given driver.
+func Wrap(d driver.Driver, opts ...Option) driver.Driver {
+	return &wrappedDriver{Driver: d, cfg: newConfig(opts)}
+}
+
+// WrapConnector returns a connector whose connections emit an ft span for every operation.
+func WrapConnector(c driver.Connector, opts ...Option) driver.Connector {
+	cfg := newConfig(opts)
+	return &wrappedConnector{Connector: c, driver: &wrappedDriver{Driver: c.Driver(), cfg: cfg}, cfg: cfg}
+}
+
+// operation is a single instrumented database operation.
+type operation struct {
+	ctx  context.Context
+	span ft.Span
+	err  error
+}
+
+func (cfg *config) getTracer() *ft.Tracer {
+	if cfg.tracer != nil {
+		return cfg.tracer
+	}
+
+	return ft.Default()
+}
+
+func (cfg *config) start(ctx context.Context, op Op, query string, opts ...ft.Option) *operation {
+	o := &operation{}
+
+	spanOpts := make([]ft.Option, 0, len(cfg.spanOpts)+len(opts)+3)
+	spanOpts = append(spanOpts, cfg.spanOpts...)
+	spanOpts = append(spanOpts, opts...)
+	spanOpts = append(spanOpts, ft.WithErr(&o.err), ft.WithSpanKind(trace.SpanKindClient))
+
+	if query != "" {
+		statement := query
+		if cfg.sanitizer != nil {
+			statement = cfg.sanitizer(query)
+		}
+		spanOpts = append(spanOpts, ft.WithAttrs(slog.String(statementAttrKey, statement)))
+	}
+
+	o.ctx, o.span = cfg.getTracer().Start(ctx, cfg.actionNamer(op, query), spanOpts...)
+
+	return o
+}
+
+// end ends the span with the error.
+func (o *operation) end(err error) {
+	o.err = err
+	o.span.End()
+}
+
+// endExec adds the number of affected rows to the span, if known, and ends it.
+func (o *operation) endExec(res driver.Result, err error) {
+	if err == nil && res != nil {
+		if n, rowsErr := res.RowsAffected(); rowsErr == nil {
+			o.span.AddAttrs(slog.Int64(rowsAffectedAttrKey, n))
+		}
+	}
+
+	o.end(err)
+}
@@ -0,0 +1,234 @@
+package ftsql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/amanbolat/ft/ftsql"
+	"github.com/amanbolat/ft/fttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFake = errors.New("fake: query failed")
+
+// fakeDriver is an in-process driver. Queries starting with FAIL return an error,
+// Exec of statements starting with SKIP returns driver.ErrSkip to force the prepared statement path,
+// and the rows of queries starting with BROKEN fail while iterating.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.HasPrefix(query, "FAIL") {
+		return nil, errFake
+	}
+
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+// CheckNamedValue accepts customValue, like drivers such as pgx accept their own types on the connection level.
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(customValue); ok {
+		nv.Value = int64(v.n)
+		return nil
+	}
+
+	return driver.ErrSkip
+}
+
+type customValue struct {
+	n int
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(query, "SKIP"):
+		return nil, driver.ErrSkip
+	case strings.HasPrefix(query, "FAIL"):
+		return nil, errFake
+	}
+
+	return driver.RowsAffected(3), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.HasPrefix(query, "FAIL"):
+		return nil, errFake
+	case strings.HasPrefix(query, "BROKEN"):
+		return &fakeRows{err: errFake}, nil
+	}
+
+	return &fakeRows{}, nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+// fakeRows returns no rows, or err if set.
+type fakeRows struct {
+	err error
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next([]driver.Value) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return io.EOF
+}
+
+var registerOnce sync.Once
+
+func openDB(t *testing.T, opts ...ftsql.Option) *sql.DB {
+	t.Helper()
+
+	registerOnce.Do(func() { sql.Register("ftsql_fake", fakeDriver{}) })
+
+	db, err := ftsql.Open("ftsql_fake", "", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestWrap_ExecAndQuery(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	db := openDB(t, ftsql.WithTracer(rec.Tracer()))
+	ctx := context.Background()
+
+	res, err := db.ExecContext(ctx, "UPDATE users SET name = 'bob' WHERE id = 42")
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	require.NoError(t, rec.Ended("db.exec").Err())
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ?", rec.Attr("db.exec", "db.statement").String())
+	assert.Equal(t, int64(3), rec.Attr("db.exec", "db.rows_affected").Int64())
+
+	var id int
+	err = db.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1", 1).Scan(&id)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, rec.Ended("db.query").Err(), "no rows is not a failure")
+
+	_, err = db.QueryContext(ctx, "FAIL SELECT 1")
+	require.ErrorIs(t, err, errFake)
+	call := rec.Ended("db.query")
+	require.ErrorIs(t, call.Err(), errFake)
+	assert.Equal(t, slog.LevelError, call.Level())
+}
+
+func TestWrap_ErrSkip(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	db := openDB(t, ftsql.WithTracer(rec.Tracer()))
+
+	_, err := db.ExecContext(context.Background(), "SKIP DELETE FROM users WHERE id = ?", 1)
+	require.NoError(t, err)
+
+	calls := rec.Calls("db.exec")
+	require.Len(t, calls, 1, "the attempt that returned driver.ErrSkip is not recorded")
+	require.NoError(t, calls[0].Err())
+	assert.Equal(t, int64(1), rec.Attr("db.exec", "db.rows_affected").Int64())
+	require.NoError(t, rec.Ended("db.prepare").Err())
+}
+
+func TestWrap_RowsEndSpanOnClose(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	db := openDB(t, ftsql.WithTracer(rec.Tracer()))
+
+	rows, err := db.QueryContext(context.Background(), "BROKEN SELECT id FROM users")
+	require.NoError(t, err)
+	assert.Empty(t, rec.Calls("db.query"), "the span ends when the rows are closed")
+
+	require.False(t, rows.Next())
+	require.ErrorIs(t, rows.Err(), errFake)
+	require.NoError(t, rows.Close())
+
+	require.ErrorIs(t, rec.Ended("db.query").Err(), errFake)
+}
+
+func TestWrap_ConnNamedValueChecker(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	db := openDB(t, ftsql.WithTracer(rec.Tracer()))
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "UPDATE t SET x = ?", customValue{n: 1})
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, "SKIP UPDATE t SET x = ?", customValue{n: 1})
+	require.NoError(t, err)
+
+	stmt, err := db.PrepareContext(ctx, "UPDATE t SET x = ?")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = stmt.Close() })
+
+	_, err = stmt.ExecContext(ctx, customValue{n: 1})
+	require.NoError(t, err)
+}
+
+func TestWrap_Tx(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	db := openDB(t,
+		ftsql.WithTracer(rec.Tracer()),
+		ftsql.WithActionNamer(func(op ftsql.Op, _ string) string { return "users_db." + string(op) }),
+	)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	tx, err = db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	assert.Len(t, rec.Calls("users_db.begin"), 2)
+	assert.Len(t, rec.Calls("users_db.commit"), 1)
+	assert.Len(t, rec.Calls("users_db.rollback"), 1)
+}
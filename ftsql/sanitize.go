@@ -0,0 +1,150 @@
+package ftsql
+
+import "strings"
+
+// SanitizeStatement replaces string and numeric literals in the SQL statement with '?',
+// so that statements can be attached to spans without leaking data.
+// String literals may contain quotes escaped by doubling them, as in standard SQL. A backslash only escapes
+// a quote in PostgreSQL escape strings like E'it\'s', use SanitizeMySQLStatement for MySQL.
+// PostgreSQL dollar-quoted strings like $$text$$ or $tag$text$tag$ are replaced as well.
+// Quoted identifiers, placeholders like $1 and identifiers containing digits are kept.
+func SanitizeStatement(query string) string {
+	return sanitize(query, false)
+}
+
+// SanitizeMySQLStatement is like SanitizeStatement, but a backslash escapes the next character in all string literals,
+// as in MySQL without the NO_BACKSLASH_ESCAPES mode. Pass it to WithStatementSanitizer for MySQL databases.
+func SanitizeMySQLStatement(query string) string {
+	return sanitize(query, true)
+}
+
+func sanitize(query string, backslashEscapes bool) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'':
+			i = skipString(query, i, backslashEscapes)
+			b.WriteByte('?')
+		case (c == 'E' || c == 'e') && i+1 < len(query) && query[i+1] == '\'' && !continuesWord(query, i):
+			i = skipString(query, i+1, true)
+			b.WriteByte('?')
+		case c == '$' && !continuesWord(query, i) && dollarQuoteTag(query, i) != "":
+			i = skipDollarQuotedString(query, i)
+			b.WriteByte('?')
+		case isDigit(c) && !continuesWord(query, i):
+			i = skipNumber(query, i)
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// skipString returns the index of the quote closing the string literal that starts at i.
+// Quotes escaped by doubling them are part of the literal, as are characters escaped with a backslash
+// if backslashEscapes is set.
+func skipString(query string, i int, backslashEscapes bool) int {
+	for i++; i < len(query); i++ {
+		if backslashEscapes && query[i] == '\\' {
+			i++
+			continue
+		}
+
+		if query[i] != '\'' {
+			continue
+		}
+
+		if i+1 < len(query) && query[i+1] == '\'' {
+			i++
+			continue
+		}
+
+		return i
+	}
+
+	return len(query) - 1
+}
+
+// dollarQuoteTag returns the opening tag of the dollar-quoted string that starts at i, e.g. "$$" or "$tag$".
+// It returns an empty string if there is none, e.g. for the placeholder $1.
+func dollarQuoteTag(query string, i int) string {
+	j := i + 1
+	for j < len(query) && (isLetter(query[j]) || query[j] == '_' || (j > i+1 && isDigit(query[j]))) {
+		j++
+	}
+
+	if j < len(query) && query[j] == '$' {
+		return query[i : j+1]
+	}
+
+	return ""
+}
+
+// skipDollarQuotedString returns the index of the last character of the dollar-quoted string that starts at i.
+func skipDollarQuotedString(query string, i int) int {
+	tag := dollarQuoteTag(query, i)
+
+	end := strings.Index(query[i+len(tag):], tag)
+	if end < 0 {
+		return len(query) - 1
+	}
+
+	return i + len(tag) + end + len(tag) - 1
+}
+
+// skipNumber returns the index of the last character of the numeric literal that starts at i.
+func skipNumber(query string, i int) int {
+	if query[i] == '0' && i+1 < len(query) && (query[i+1] == 'x' || query[i+1] == 'X') {
+		i += 2
+		for i < len(query) && isHexDigit(query[i]) {
+			i++
+		}
+
+		return i - 1
+	}
+
+	for i++; i < len(query); i++ {
+		c := query[i]
+		if isDigit(c) || c == '.' {
+			continue
+		}
+
+		if (c == 'e' || c == 'E') && i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '-' || query[i+1] == '+') {
+			i++
+			continue
+		}
+
+		break
+	}
+
+	return i - 1
+}
+
+// continuesWord reports whether the character at i is part of an identifier or a placeholder.
+func continuesWord(query string, i int) bool {
+	if i == 0 {
+		return false
+	}
+
+	prev := query[i-1]
+
+	return isLetter(prev) || isDigit(prev) || prev == '_' || prev == '$' || prev == '@' || prev == ':'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,72 @@
+package ftsql_test
+
+import (
+	"testing"
+
+	"github.com/amanbolat/ft/ftsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "SELECT * FROM users WHERE email = 'a@b.com' AND age > 18",
+			expected: "SELECT * FROM users WHERE email = ? AND age > ?",
+		},
+		{
+			query:    "INSERT INTO t2 (name, score) VALUES ('O''Brien', 3.14e-2), ('x', 0xFF)",
+			expected: "INSERT INTO t2 (name, score) VALUES (?, ?), (?, ?)",
+		},
+		{
+			query:    `SELECT "col1" FROM tbl_2 WHERE id = $1 AND v = @p2 AND w = :name3 LIMIT 10`,
+			expected: `SELECT "col1" FROM tbl_2 WHERE id = $1 AND v = @p2 AND w = :name3 LIMIT ?`,
+		},
+		{
+			query:    "SELECT 'unterminated",
+			expected: "SELECT ?",
+		},
+		{
+			query:    `SELECT * FROM f WHERE path = 'C:\' AND password = 'hunter2'`,
+			expected: "SELECT * FROM f WHERE path = ? AND password = ?",
+		},
+		{
+			query:    `SELECT E'it\'s a secret', e'C:\\', 'C:\' FROM t WHERE name = 'secret'`,
+			expected: "SELECT ?, ?, ? FROM t WHERE name = ?",
+		},
+		{
+			query:    "SELECT $$it's a secret$$, $body$ $$nested$$ secret $body$ FROM t WHERE id = $1",
+			expected: "SELECT ?, ? FROM t WHERE id = $1",
+		},
+		{
+			query:    "SELECT $tag$unterminated secret",
+			expected: "SELECT ?",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ftsql.SanitizeStatement(tt.query), tt.query)
+	}
+}
+
+func TestSanitizeMySQLStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    `UPDATE users SET name = 'it\'s a secret' WHERE note = 'C:\\' AND id = 1`,
+			expected: "UPDATE users SET name = ? WHERE note = ? AND id = ?",
+		},
+		{
+			query:    "INSERT INTO t2 (name, score) VALUES ('O''Brien', 3.14e-2)",
+			expected: "INSERT INTO t2 (name, score) VALUES (?, ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ftsql.SanitizeMySQLStatement(tt.query), tt.query)
+	}
+}
@@ -0,0 +1,365 @@
+// Package fttest provides an in-memory recorder for asserting on ft spans in unit tests.
+//
+//	func TestService_Do(t *testing.T) {
+//		rec := fttest.NewRecorder(t)
+//
+//		err := svc.Do(context.Background())
+//
+//		require.ErrorIs(t, rec.Ended("svc.Do").Err(), err)
+//		assert.Equal(t, "42", rec.Attr("svc.Do", "user_id").String())
+//	}
+package fttest
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amanbolat/ft"
+	"github.com/jonboulle/clockwork"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	msgActionStarted = "action started"
+	msgActionEnded   = "action ended"
+)
+
+type config struct {
+	clock      *clockwork.FakeClock
+	setDefault bool
+	ftOpts     []ft.Option
+}
+
+// Option configures a Recorder.
+type Option func(cfg *config)
+
+// WithClock sets the fake clock used by the recorded Tracer. By default, a new fake clock is created.
+func WithClock(c *clockwork.FakeClock) Option {
+	return func(cfg *config) {
+		if c != nil {
+			cfg.clock = c
+		}
+	}
+}
+
+// WithTracerOptions adds options to the recorded Tracer. They are applied after the options of the recorder.
+func WithTracerOptions(opts ...ft.Option) Option {
+	return func(cfg *config) {
+		cfg.ftOpts = append(cfg.ftOpts, opts...)
+	}
+}
+
+// WithoutDefault keeps the default ft Tracer untouched. Use Recorder.Tracer to start spans in this case,
+// which allows the test to run in parallel with other tests.
+func WithoutDefault() Option {
+	return func(cfg *config) {
+		cfg.setDefault = false
+	}
+}
+
+// Recorder records the logs, spans and metrics produced by an ft Tracer.
+type Recorder struct {
+	t       testing.TB
+	tracer  *ft.Tracer
+	clock   *clockwork.FakeClock
+	spans   *tracetest.SpanRecorder
+	reader  *sdkmetric.ManualReader
+	handler *handler
+}
+
+// NewRecorder creates a Recorder and installs its Tracer as the default ft Tracer for the duration of the test.
+// The previous default Tracer is restored on t.Cleanup.
+// The Tracer has tracing and metrics enabled, appends span attributes to the OpenTelemetry spans
+// and measures durations with a fake clock, see Recorder.Clock.
+func NewRecorder(t testing.TB, opts ...Option) *Recorder {
+	t.Helper()
+
+	cfg := &config{setDefault: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.clock == nil {
+		cfg.clock = clockwork.NewFakeClock()
+	}
+
+	rec := &Recorder{
+		t:       t,
+		clock:   cfg.clock,
+		spans:   tracetest.NewSpanRecorder(),
+		reader:  sdkmetric.NewManualReader(),
+		handler: &handler{store: &recordStore{}},
+	}
+
+	tracerOpts := []ft.Option{
+		ft.WithLogger(slog.New(rec.handler)),
+		ft.WithClock(rec.clock),
+		ft.WithTracingEnabled(true),
+		ft.WithMetricsEnabled(true),
+		ft.WithAppendOtelAttrs(true),
+		ft.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec.spans))),
+		ft.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(rec.reader))),
+	}
+	rec.tracer = ft.New(append(tracerOpts, cfg.ftOpts...)...)
+
+	if cfg.setDefault {
+		prev := ft.Default()
+		ft.SetDefault(rec.tracer)
+		t.Cleanup(func() { ft.SetDefault(prev) })
+	}
+
+	return rec
+}
+
+// Tracer returns the recorded Tracer.
+func (r *Recorder) Tracer() *ft.Tracer {
+	return r.tracer
+}
+
+// Clock returns the fake clock used by the recorded Tracer.
+func (r *Recorder) Clock() *clockwork.FakeClock {
+	return r.clock
+}
+
+// Records returns all log records written by the recorded Tracer.
+func (r *Recorder) Records() []slog.Record {
+	return r.handler.store.all()
+}
+
+// Started returns the number of started calls of the action.
+func (r *Recorder) Started(action string) int {
+	var n int
+	for _, rec := range r.handler.store.all() {
+		if rec.Message == msgActionStarted && actionOf(rec) == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Calls returns the ended calls of the action in the order they ended.
+// The OpenTelemetry span of a call is matched by the span ID in the trace correlation fields of its record.
+func (r *Recorder) Calls(action string) []*Call {
+	spanIDKey := ft.DefaultTraceLogFields().SpanIDKey
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range r.Spans(action) {
+		spans[s.SpanContext().SpanID().String()] = s
+	}
+
+	var calls []*Call
+	for _, rec := range r.handler.store.all() {
+		if rec.Message == msgActionEnded && actionOf(rec) == action {
+			call := newCall(action, rec)
+			if spanID, ok := call.Attr(spanIDKey); ok {
+				call.span = spans[spanID.String()]
+			}
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Ended returns the last ended call of the action. It fails the test if the action has not ended.
+func (r *Recorder) Ended(action string) *Call {
+	r.t.Helper()
+
+	calls := r.Calls(action)
+	if len(calls) == 0 {
+		r.t.Fatalf("fttest: action %q has not ended", action)
+		return nil
+	}
+
+	return calls[len(calls)-1]
+}
+
+// Attr returns the value of the attribute of the last ended call of the action.
+// It fails the test if the action has not ended, and returns the zero Value if the attribute is absent.
+func (r *Recorder) Attr(action, key string) slog.Value {
+	r.t.Helper()
+
+	v, _ := r.Ended(action).Attr(key)
+	return v
+}
+
+// Duration returns the duration of the last ended call of the action. It fails the test if the action has not ended.
+func (r *Recorder) Duration(action string) time.Duration {
+	r.t.Helper()
+
+	return r.Ended(action).Duration()
+}
+
+// Spans returns the ended OpenTelemetry spans of the action in the order they ended.
+func (r *Recorder) Spans(action string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, s := range r.spans.Ended() {
+		if s.Name() == action {
+			spans = append(spans, s)
+		}
+	}
+
+	return spans
+}
+
+// Metrics collects the metrics recorded so far.
+func (r *Recorder) Metrics() metricdata.ResourceMetrics {
+	r.t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.reader.Collect(context.Background(), &rm); err != nil {
+		r.t.Fatalf("fttest: collect metrics: %v", err)
+	}
+
+	return rm
+}
+
+// Call is an ended call of an action as seen in the "action ended" log record.
+type Call struct {
+	action string
+	record slog.Record
+	span   sdktrace.ReadOnlySpan
+}
+
+func newCall(action string, record slog.Record) *Call {
+	return &Call{action: action, record: record}
+}
+
+// Action returns the name of the action.
+func (c *Call) Action() string {
+	return c.action
+}
+
+// Level returns the level the call was logged at.
+func (c *Call) Level() slog.Level {
+	return c.record.Level
+}
+
+// Err returns the error the call ended with, or nil if it succeeded.
+func (c *Call) Err() error {
+	v, ok := c.Attr("error")
+	if !ok {
+		return nil
+	}
+
+	err, _ := v.Any().(error)
+	return err
+}
+
+// Attr returns the value of the attribute with the given key and reports whether it was found.
+// When the key occurs several times, the last attribute wins.
+func (c *Call) Attr(key string) (slog.Value, bool) {
+	var (
+		value slog.Value
+		found bool
+	)
+
+	c.record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == key {
+			value, found = attr.Value, true
+		}
+		return true
+	})
+
+	return value, found
+}
+
+// Attrs returns all attributes of the call.
+func (c *Call) Attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, c.record.NumAttrs())
+	c.record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	return attrs
+}
+
+// Duration returns the duration of the call.
+func (c *Call) Duration() time.Duration {
+	if v, ok := c.Attr("duration_ms"); ok {
+		return time.Duration(v.Float64() * float64(time.Millisecond))
+	}
+
+	if v, ok := c.Attr("duration_s"); ok {
+		return time.Duration(v.Float64() * float64(time.Second))
+	}
+
+	return 0
+}
+
+// Span returns the OpenTelemetry span of the call, or nil if it cannot be matched to the call,
+// e.g. because tracing was disabled for the call or the trace correlation fields were changed with ft.WithTraceLogFields.
+func (c *Call) Span() sdktrace.ReadOnlySpan {
+	return c.span
+}
+
+func actionOf(r slog.Record) string {
+	var action string
+	r.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "action" {
+			action = attr.Value.String()
+			return false
+		}
+		return true
+	})
+
+	return action
+}
+
+// recordStore is shared by a handler and the handlers derived from it.
+type recordStore struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (s *recordStore) add(r slog.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = append(s.records, r)
+}
+
+func (s *recordStore) all() []slog.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]slog.Record, len(s.records))
+	copy(records, s.records)
+
+	return records
+}
+
+// handler is a slog.Handler that stores all records regardless of their level.
+type handler struct {
+	store *recordStore
+	attrs []slog.Attr
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	r = r.Clone()
+	if len(h.attrs) > 0 {
+		r.AddAttrs(h.attrs...)
+	}
+	h.store.add(r)
+
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{store: h.store, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *handler) WithGroup(string) slog.Handler {
+	return h
+}
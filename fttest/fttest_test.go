@@ -0,0 +1,114 @@
+package fttest_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/amanbolat/ft"
+	"github.com/amanbolat/ft/fttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := fttest.NewRecorder(t)
+
+	testErr := errors.New("test error")
+	do := func(ctx context.Context, userID string) (err error) {
+		_, span := ft.Start(ctx, "svc.Do", ft.WithErr(&err), ft.WithAttrs(slog.String("user_id", userID)))
+		defer span.End()
+
+		rec.Clock().Advance(150 * time.Millisecond)
+		span.AddAttrs(slog.Int("attempt", 2))
+
+		if userID == "2" {
+			return testErr
+		}
+
+		return nil
+	}
+
+	require.NoError(t, do(context.Background(), "1"))
+	require.ErrorIs(t, do(context.Background(), "2"), testErr)
+
+	assert.Equal(t, 2, rec.Started("svc.Do"))
+	require.Len(t, rec.Calls("svc.Do"), 2)
+	assert.NoError(t, rec.Calls("svc.Do")[0].Err())
+
+	ended := rec.Ended("svc.Do")
+	assert.ErrorIs(t, ended.Err(), testErr)
+	assert.Equal(t, slog.LevelError, ended.Level())
+	assert.Equal(t, "2", rec.Attr("svc.Do", "user_id").String())
+	assert.Equal(t, int64(2), rec.Attr("svc.Do", "attempt").Int64())
+	assert.Equal(t, 150*time.Millisecond, rec.Duration("svc.Do"))
+
+	spans := rec.Spans("svc.Do")
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, spans[1], ended.Span())
+	assert.Equal(t, 150*time.Millisecond, spans[1].EndTime().Sub(spans[1].StartTime()))
+
+	var found bool
+	for _, scopeMetrics := range rec.Metrics().ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			found = found || m.Name == "svc.Do_counter"
+		}
+	}
+	assert.True(t, found, "expected the call counter to be recorded")
+}
+
+func TestRecorder_RestoresDefault(t *testing.T) {
+	prev := ft.Default()
+
+	t.Run("recorder", func(t *testing.T) {
+		rec := fttest.NewRecorder(t)
+		assert.Same(t, rec.Tracer(), ft.Default())
+	})
+
+	assert.Same(t, prev, ft.Default())
+}
+
+func TestRecorder_WithoutDefault(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault(), fttest.WithTracerOptions(
+		ft.WithDurationMetricUnit(ft.DurationMetricUnitSecond),
+	))
+	assert.NotSame(t, rec.Tracer(), ft.Default())
+
+	_ = rec.Tracer().Run(context.Background(), "svc.Run", func(ctx context.Context) error {
+		rec.Clock().Advance(2 * time.Second)
+		return nil
+	})
+
+	assert.NoError(t, rec.Ended("svc.Run").Err())
+	assert.Equal(t, 2*time.Second, rec.Duration("svc.Run"))
+}
+
+func TestRecorder_CallSpans(t *testing.T) {
+	t.Parallel()
+
+	rec := fttest.NewRecorder(t, fttest.WithoutDefault())
+	ctx := context.Background()
+
+	_, first := rec.Tracer().Start(ctx, "svc.Do")
+	_, untraced := rec.Tracer().Start(ctx, "svc.Do", ft.WithTracingEnabled(false))
+	_, last := rec.Tracer().Start(ctx, "svc.Do")
+	last.End()
+	untraced.End()
+	first.End()
+
+	calls := rec.Calls("svc.Do")
+	spans := rec.Spans("svc.Do")
+	require.Len(t, calls, 3)
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, spans[0], calls[0].Span())
+	assert.Nil(t, calls[1].Span(), "calls without a span must not be matched")
+	assert.Equal(t, spans[1], calls[2].Span())
+	assert.NotEqual(t, calls[0].Span().SpanContext().SpanID(), calls[2].Span().SpanContext().SpanID())
+}
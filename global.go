@@ -1,68 +1,146 @@
 package ft
 
 import (
+	"context"
 	"log/slog"
-	"os"
+	"time"
 
 	"github.com/jonboulle/clockwork"
-	"github.com/samber/lo"
 	"go.uber.org/atomic"
 )
 
-var (
-	globalLogger             = atomic.NewPointer(slog.New(slog.NewTextHandler(os.Stdout, nil)))
-	globalTracingEnabled     = atomic.NewBool(false)
-	globalMetricsEnabled     = atomic.NewBool(false)
-	globalAppendOtelAttrs    = atomic.NewBool(false)
-	globalDurationMetricUnit = atomic.NewString(DurationMetricUnitMillisecond)
-	globalClock              = atomic.NewPointer[clockwork.Clock](lo.ToPtr(clockwork.NewRealClock()))
+var defaultTracer = atomic.NewPointer(New())
 
-	globalLogLevelEndOnSuccess slog.LevelVar
-	globalLogLevelEndOnFailure slog.LevelVar
-)
+// Default returns the Tracer used by the package-level functions.
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// SetDefault replaces the Tracer used by the package-level functions. Does nothing if nil Tracer is provided.
+func SetDefault(t *Tracer) {
+	if t == nil {
+		return
+	}
+
+	defaultTracer.Store(t)
+}
 
-func init() {
-	globalLogLevelEndOnSuccess.Set(slog.LevelInfo)
-	globalLogLevelEndOnFailure.Set(slog.LevelError)
+// FlushSuppressedLogs reports the records suppressed by the LogSampler of the default Tracer right away,
+// see Tracer.FlushSuppressedLogs.
+func FlushSuppressedLogs(ctx context.Context) {
+	Default().flushSuppressedLogs(ctx, callerPC(1))
 }
 
 func SetDurationMetricUnit(unit string) {
-	switch unit {
-	case DurationMetricUnitMillisecond, DurationMetricUnitSecond:
-		globalDurationMetricUnit.Store(unit)
-	default:
-		globalDurationMetricUnit.Store(DurationMetricUnitMillisecond)
-	}
+	Default().configure(WithDurationMetricUnit(unit))
 }
 
 func SetDefaultLogger(l *slog.Logger) {
-	if l == nil {
-		return
-	}
-
-	globalLogger.Store(l)
+	Default().configure(WithLogger(l))
 }
 
 func SetLogLevelOnFailure(level slog.Level) {
-	globalLogLevelEndOnFailure.Set(level)
+	Default().configure(WithLogLevelOnFailure(level))
 }
 
 func SetLogLevelOnSuccess(level slog.Level) {
-	globalLogLevelEndOnSuccess.Set(level)
+	Default().configure(WithLogLevelOnSuccess(level))
+}
+
+func SetLogLevelOnEvent(level slog.Level) {
+	Default().configure(WithLogLevelOnEvent(level))
+}
+
+func SetLogLevelOnStart(level slog.Leveler) {
+	Default().configure(WithLogLevelOnStart(level))
+}
+
+func SetStartLogEnabled(v bool) {
+	Default().configure(WithStartLogEnabled(v))
+}
+
+func SetLogLevelOnSlow(level slog.Level) {
+	Default().configure(WithLogLevelOnSlow(level))
+}
+
+func SetSlowThreshold(d time.Duration) {
+	Default().configure(WithSlowThreshold(d))
 }
 
 func SetTracingEnabled(v bool) {
-	globalTracingEnabled.Store(v)
+	Default().configure(WithTracingEnabled(v))
 }
 
 func SetMetricsEnabled(v bool) {
-	globalMetricsEnabled.Store(v)
+	Default().configure(WithMetricsEnabled(v))
 }
 
 func SetClock(c clockwork.Clock) {
-	globalClock.Store(&c)
+	Default().configure(WithClock(c))
 }
 
 func SetAppendOtelAttrs(v bool) {
-	globalAppendOtelAttrs.Store(v)
+	Default().configure(WithAppendOtelAttrs(v))
+}
+
+func SetLogOutput(output LogOutput) {
+	Default().configure(WithLogOutput(output))
+}
+
+func SetBaggageAttrs(v bool) {
+	Default().configure(WithBaggageAttrs(v))
+}
+
+func SetActionPath(v bool) {
+	Default().configure(WithActionPath(v))
+}
+
+func SetActionPathLimits(maxDepth, maxLength int) {
+	Default().configure(WithActionPathLimits(maxDepth, maxLength))
+}
+
+func SetPanicMode(mode PanicMode) {
+	Default().configure(WithPanicMode(mode))
+}
+
+func SetTraceLogFields(fields TraceLogFields) {
+	Default().configure(WithTraceLogFields(fields))
+}
+
+func SetLogSampler(sampler LogSampler) {
+	Default().configure(WithLogSampler(sampler))
+}
+
+func SetLogSuppressionReportInterval(d time.Duration) {
+	Default().configure(WithLogSuppressionReportInterval(d))
+}
+
+func SetTraceLogMode(mode TraceLogMode) {
+	Default().configure(WithTraceLogMode(mode))
+}
+
+func SetLogLevelOnUnsampled(level slog.Level) {
+	Default().configure(WithLogLevelOnUnsampled(level))
+}
+
+func SetMetricAttrs(keys ...string) {
+	Default().configure(func(cfg *SpanConfig) {
+		cfg.metricAttrKeys = keys
+	})
+}
+
+func SetMetricAttrCardinalityLimit(n int) {
+	Default().configure(WithMetricAttrCardinalityLimit(n))
+}
+
+func SetMetricNaming(naming MetricNaming) {
+	Default().configure(WithMetricNaming(naming))
+}
+
+func SetDurationBuckets(bounds ...float64) {
+	Default().configure(WithDurationBuckets(bounds...))
+}
+
+func SetActionDurationBuckets(pattern string, bounds ...float64) {
+	Default().configure(WithActionDurationBuckets(pattern, bounds...))
 }
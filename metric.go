@@ -0,0 +1,285 @@
+package ft
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"path"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// DefaultMetricAttrCardinalityLimit is the default number of distinct values
+	// a metric attribute key can have before new values are bucketed.
+	DefaultMetricAttrCardinalityLimit = 100
+	// MetricAttrOverflowValue replaces the values of a metric attribute key once it exceeds the cardinality limit.
+	MetricAttrOverflowValue = "_other"
+
+	maxInstrumentNameLength = 255
+)
+
+// MetricNaming defines how metric instruments are named.
+type MetricNaming int
+
+const (
+	// MetricNamingPerAction creates separate instruments for every action, e.g. <action>_counter.
+	// This is the default.
+	MetricNamingPerAction MetricNaming = iota
+	// MetricNamingShared records all actions into a single set of instruments (ft.calls, ft.duration, etc.)
+	// and puts the action name into the action attribute.
+	MetricNamingShared
+)
+
+// metricNames holds the names of the instruments recorded for an action.
+type metricNames struct {
+	calls    string
+	outcomes string
+	panics   string
+	slow     string
+	active   string
+	duration string
+	// durationDescription is the description of the duration histogram.
+	durationDescription string
+}
+
+func (cfg *SpanConfig) metricNames(action string) metricNames {
+	if cfg.metricNaming == MetricNamingShared {
+		return metricNames{
+			calls:               "ft.calls",
+			outcomes:            "ft.outcomes",
+			panics:              "ft.panics",
+			slow:                "ft.slow_calls",
+			active:              "ft.active",
+			duration:            "ft.duration",
+			durationDescription: "action duration",
+		}
+	}
+
+	durationSuffix := "_duration_milliseconds"
+	if cfg.durationMetricUnit == DurationMetricUnitSecond {
+		durationSuffix = "_duration_seconds"
+	}
+
+	return metricNames{
+		calls:               instrumentName(action, "_counter"),
+		outcomes:            instrumentName(action, "_outcome_counter"),
+		panics:              instrumentName(action, "_panic_counter"),
+		slow:                instrumentName(action, "_slow_counter"),
+		active:              instrumentName(action, "_active"),
+		duration:            instrumentName(action, durationSuffix),
+		durationDescription: fmt.Sprintf("[%s] action duration", action),
+	}
+}
+
+// instrumentName returns the name of the instrument of the action with the suffix, e.g. main.Do_counter.
+// The name must conform to the OpenTelemetry instrument name syntax: it must start with a letter,
+// contain only alphanumeric characters, '_', '.', '-' and '/', and be at most 255 characters long.
+// Otherwise, invalid characters of the action are replaced with '_', the action is cut to keep the suffix
+// within the limit and a hash of the action is appended, so that e.g. "a b" and "a_b" don't share instruments.
+func instrumentName(action, suffix string) string {
+	if name := action + suffix; isValidInstrumentName(name) {
+		return name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(action))
+	hash := fmt.Sprintf("_%08x", h.Sum32())
+	maxLength := maxInstrumentNameLength - len(hash) - len(suffix)
+
+	b := make([]byte, 0, len(action)+2)
+	if len(action) == 0 || !isASCIILetter(action[0]) {
+		b = append(b, 'a', '_')
+	}
+
+	for i := 0; i < len(action) && len(b) < maxLength; i++ {
+		if isInstrumentNameChar(action[i]) {
+			b = append(b, action[i])
+		} else {
+			b = append(b, '_')
+		}
+	}
+
+	return string(b) + hash + suffix
+}
+
+func isValidInstrumentName(name string) bool {
+	if len(name) == 0 || len(name) > maxInstrumentNameLength || !isASCIILetter(name[0]) {
+		return false
+	}
+
+	for i := 1; i < len(name); i++ {
+		if !isInstrumentNameChar(name[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isInstrumentNameChar(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9') || c == '_' || c == '.' || c == '-' || c == '/'
+}
+
+// instrumentKey identifies a cached metric instrument.
+// Instruments are keyed by provider, so the same name never resolves to an instrument of another provider.
+type instrumentKey struct {
+	provider metric.MeterProvider
+	name     string
+}
+
+// int64Counter returns the cached counter with the given name, creating it on first use.
+func (t *Tracer) int64Counter(mp metric.MeterProvider, name string) (metric.Int64Counter, bool) {
+	key := instrumentKey{provider: mp, name: name}
+	counter, ok := t.int64Counters.Load(key)
+	if ok {
+		return counter, true
+	}
+
+	counter, err := mp.Meter(instrumentationName).Int64Counter(name)
+	if err != nil {
+		return nil, false
+	}
+	t.int64Counters.Store(key, counter)
+
+	return counter, true
+}
+
+// int64UpDownCounter returns the cached up-down counter with the given name, creating it on first use.
+func (t *Tracer) int64UpDownCounter(mp metric.MeterProvider, name string) (metric.Int64UpDownCounter, bool) {
+	key := instrumentKey{provider: mp, name: name}
+	counter, ok := t.int64UpDownCounters.Load(key)
+	if ok {
+		return counter, true
+	}
+
+	counter, err := mp.Meter(instrumentationName).Int64UpDownCounter(name)
+	if err != nil {
+		return nil, false
+	}
+	t.int64UpDownCounters.Store(key, counter)
+
+	return counter, true
+}
+
+// durationHistogram returns the cached duration histogram of the action, creating it on first use.
+// Bucket boundaries are resolved only when the histogram is created.
+func (t *Tracer) durationHistogram(cfg *SpanConfig, mp metric.MeterProvider, names metricNames, action string) (metric.Float64Histogram, bool) {
+	key := instrumentKey{provider: mp, name: names.duration}
+	histogram, ok := t.durationHistograms.Load(key)
+	if ok {
+		return histogram, true
+	}
+
+	opts := []metric.Float64HistogramOption{
+		metric.WithUnit(cfg.durationMetricUnit),
+		metric.WithDescription(names.durationDescription),
+	}
+	if bounds := cfg.durationBuckets(action); len(bounds) > 0 {
+		opts = append(opts, metric.WithExplicitBucketBoundaries(bounds...))
+	}
+
+	histogram, err := mp.Meter(instrumentationName).Float64Histogram(names.duration, opts...)
+	if err != nil {
+		return nil, false
+	}
+	t.durationHistograms.Store(key, histogram)
+
+	return histogram, true
+}
+
+// actionBuckets holds the duration histogram bucket boundaries for actions matching the pattern.
+type actionBuckets struct {
+	pattern string
+	bounds  []float64
+}
+
+// durationBuckets returns the bucket boundaries of the action's duration histogram.
+// An exact action match takes precedence over patterns, which are checked in the order they were added.
+// With MetricNamingShared all actions share one histogram, so only the global boundaries apply.
+func (cfg *SpanConfig) durationBuckets(action string) []float64 {
+	if cfg.metricNaming == MetricNamingShared {
+		return cfg.defaultDurationBuckets
+	}
+
+	for _, b := range cfg.actionDurationBuckets {
+		if b.pattern == action {
+			return b.bounds
+		}
+	}
+
+	for _, b := range cfg.actionDurationBuckets {
+		if matched, err := path.Match(b.pattern, action); err == nil && matched {
+			return b.bounds
+		}
+	}
+
+	return cfg.defaultDurationBuckets
+}
+
+// attrValueSet tracks the distinct values seen for a metric attribute key.
+type attrValueSet struct {
+	mu     sync.Mutex
+	values map[string]struct{}
+}
+
+// admit reports whether the value may be used as is, remembering it if the limit allows.
+func (s *attrValueSet) admit(value string, limit int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[value]; ok {
+		return true
+	}
+
+	if limit > 0 && len(s.values) >= limit {
+		return false
+	}
+	s.values[value] = struct{}{}
+
+	return true
+}
+
+// metricAttrs converts the attributes whose keys are allowlisted via WithMetricAttrs to metric attributes.
+// When the same key occurs several times, the last attribute wins.
+// Values of a key that exceeded the cardinality limit are replaced with MetricAttrOverflowValue.
+// With MetricNamingShared the action attribute is added as well.
+func (t *Tracer) metricAttrs(cfg *SpanConfig, action string, attrs []slog.Attr) []attribute.KeyValue {
+	shared := cfg.metricNaming == MetricNamingShared
+	if len(cfg.metricAttrKeys) == 0 && !shared {
+		return nil
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(cfg.metricAttrKeys)+2)
+	if shared {
+		kvs = append(kvs, attribute.String("action", action))
+	}
+
+	for _, key := range cfg.metricAttrKeys {
+		for i := len(attrs) - 1; i >= 0; i-- {
+			if attrs[i].Key != key {
+				continue
+			}
+
+			kv := mapSlogAttrToOtel(attrs[i])
+			values, _ := t.metricAttrValues.LoadOrCompute(key, func() *attrValueSet {
+				return &attrValueSet{values: map[string]struct{}{}}
+			})
+			if !values.admit(kv.Value.Emit(), cfg.metricAttrCardinalityLimit) {
+				kv = attribute.String(key, MetricAttrOverflowValue)
+			}
+
+			kvs = append(kvs, kv)
+
+			break
+		}
+	}
+
+	return kvs
+}
@@ -0,0 +1,82 @@
+package ft
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// LogOutput defines where span records are written.
+type LogOutput int
+
+const (
+	// LogOutputSlog writes records to the slog.Logger. This is the default.
+	LogOutputSlog LogOutput = iota
+	// LogOutputOtel emits records as OpenTelemetry log records through the LoggerProvider.
+	LogOutputOtel
+	// LogOutputBoth writes records to the slog.Logger and emits them as OpenTelemetry log records.
+	LogOutputBoth
+)
+
+func (cfg *SpanConfig) getLoggerProvider() log.LoggerProvider {
+	if cfg.loggerProvider != nil {
+		return cfg.loggerProvider
+	}
+
+	return global.GetLoggerProvider()
+}
+
+// emitOtelLog emits the record as an OpenTelemetry log record. The trace context is taken from ctx by the SDK.
+func emitOtelLog(ctx context.Context, cfg *SpanConfig, msg string, level slog.Level, now time.Time, attrs []slog.Attr) {
+	var r log.Record
+	r.SetTimestamp(now)
+	r.SetObservedTimestamp(now)
+	r.SetSeverity(otelSeverity(level))
+	r.SetSeverityText(level.String())
+	r.SetBody(log.StringValue(msg))
+
+	for _, attr := range attrs {
+		r.AddAttributes(mapOtelAttrToLog(mapSlogAttrToOtel(attr)))
+	}
+
+	cfg.getLoggerProvider().Logger(instrumentationName).Emit(ctx, r)
+}
+
+// otelSeverity maps a slog.Level to the OpenTelemetry severity number,
+// e.g. slog.LevelInfo to log.SeverityInfo and slog.LevelInfo+1 to log.SeverityInfo2.
+func otelSeverity(level slog.Level) log.Severity {
+	severity := int(level) + int(log.SeverityInfo)
+
+	switch {
+	case severity < int(log.SeverityTrace1):
+		return log.SeverityTrace1
+	case severity > int(log.SeverityFatal4):
+		return log.SeverityFatal4
+	default:
+		return log.Severity(severity)
+	}
+}
+
+// mapOtelAttrToLog converts an attribute.KeyValue to a log.KeyValue.
+func mapOtelAttrToLog(kv attribute.KeyValue) log.KeyValue {
+	key := string(kv.Key)
+
+	switch kv.Value.Type() {
+	case attribute.BOOL:
+		return log.Bool(key, kv.Value.AsBool())
+	case attribute.INT64:
+		return log.Int64(key, kv.Value.AsInt64())
+	case attribute.FLOAT64:
+		return log.Float64(key, kv.Value.AsFloat64())
+	case attribute.STRING:
+		return log.String(key, kv.Value.AsString())
+	case attribute.INVALID, attribute.BOOLSLICE, attribute.INT64SLICE, attribute.FLOAT64SLICE, attribute.STRINGSLICE:
+		fallthrough
+	default:
+		return log.String(key, kv.Value.Emit())
+	}
+}
@@ -0,0 +1,32 @@
+package ft
+
+import "fmt"
+
+// PanicMode defines what span.End does after it has recorded a panic.
+type PanicMode int
+
+const (
+	// PanicModeRepanic re-panics with the original value. This is the default.
+	PanicModeRepanic PanicMode = iota
+	// PanicModeConvert stops the panic and stores a *PanicError in the error passed via WithErr.
+	// If WithErr was not used, End re-panics as with PanicModeRepanic.
+	PanicModeConvert
+)
+
+// PanicError is the error recorded when an instrumented function panics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the moment the panic was recovered.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
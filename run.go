@@ -0,0 +1,71 @@
+package ft
+
+import "context"
+
+// Run starts a span for the action using the default Tracer, calls fn with the span context and ends the span
+// with the error returned by fn. Panics of fn are recorded according to the configured PanicMode.
+func Run(ctx context.Context, action string, fn func(ctx context.Context) error, opts ...Option) error {
+	return Default().run(ctx, action, callerPC(1), fn, opts)
+}
+
+// Run1 is like Run for functions returning a value and an error.
+func Run1[T any](ctx context.Context, action string, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	return run1(Default(), ctx, action, callerPC(1), fn, opts)
+}
+
+// Run2 is like Run for functions returning two values and an error.
+func Run2[T, U any](ctx context.Context, action string, fn func(ctx context.Context) (T, U, error), opts ...Option) (T, U, error) {
+	return run2(Default(), ctx, action, callerPC(1), fn, opts)
+}
+
+// RunWith1 is like Run1 but uses the given Tracer. Methods can't have type parameters, hence the function.
+func RunWith1[T any](t *Tracer, ctx context.Context, action string, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	return run1(t, ctx, action, callerPC(1), fn, opts)
+}
+
+// RunWith2 is like Run2 but uses the given Tracer.
+func RunWith2[T, U any](t *Tracer, ctx context.Context, action string, fn func(ctx context.Context) (T, U, error), opts ...Option) (T, U, error) {
+	return run2(t, ctx, action, callerPC(1), fn, opts)
+}
+
+func run1[T any](t *Tracer, ctx context.Context, action string, pc uintptr, fn func(ctx context.Context) (T, error), opts []Option) (T, error) {
+	var res T
+	err := t.run(ctx, action, pc, func(ctx context.Context) error {
+		var err error
+		res, err = fn(ctx)
+		return err
+	}, opts)
+
+	return res, err
+}
+
+func run2[T, U any](t *Tracer, ctx context.Context, action string, pc uintptr, fn func(ctx context.Context) (T, U, error), opts []Option) (T, U, error) {
+	var (
+		res1 T
+		res2 U
+	)
+	err := t.run(ctx, action, pc, func(ctx context.Context) error {
+		var err error
+		res1, res2, err = fn(ctx)
+		return err
+	}, opts)
+
+	return res1, res2, err
+}
+
+// Run starts a span for the action, calls fn with the span context and ends the span
+// with the error returned by fn. Panics of fn are recorded according to the configured PanicMode.
+func (t *Tracer) Run(ctx context.Context, action string, fn func(ctx context.Context) error, opts ...Option) error {
+	return t.run(ctx, action, callerPC(1), fn, opts)
+}
+
+func (t *Tracer) run(ctx context.Context, action string, pc uintptr, fn func(ctx context.Context) error, opts []Option) (err error) {
+	spanOpts := make([]Option, 0, len(opts)+1)
+	spanOpts = append(spanOpts, opts...)
+	spanOpts = append(spanOpts, WithErr(&err))
+
+	ctx, span := t.start(ctx, action, pc, spanOpts)
+	defer span.End()
+
+	return fn(ctx)
+}
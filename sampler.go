@@ -0,0 +1,268 @@
+package ft
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultLogSuppressionReportInterval is the default minimum interval between two reports
+	// of the records suppressed by a LogSampler for the same action.
+	DefaultLogSuppressionReportInterval = time.Minute
+
+	logSamplingAttrKey = "log_sampling"
+)
+
+// TraceLogMode defines how the records of actions whose OpenTelemetry span is not sampled are logged.
+// Records of failed actions are always logged, and all records are logged when tracing is disabled.
+type TraceLogMode int
+
+const (
+	// TraceLogModeOff logs records regardless of the trace sampling decision. This is the default.
+	TraceLogModeOff TraceLogMode = iota
+	// TraceLogModeSuppress drops the records of actions whose span is not sampled.
+	TraceLogModeSuppress
+	// TraceLogModeDowngrade logs the records of actions whose span is not sampled at the unsampled level.
+	TraceLogModeDowngrade
+)
+
+// LogSampler decides whether the records of an action are logged.
+// Sampling only applies to logs, metrics and traces are always recorded.
+// Implementations must be safe for concurrent use.
+//
+// The decision is made once per span when it starts, with the parameters of the start record even if it is disabled,
+// and applies to all records of the span. The end record of a failed or slow span that was not sampled
+// is passed to the sampler again, so that samplers like the one of NewKeepFailuresSampler can keep it.
+type LogSampler interface {
+	Sample(ctx context.Context, p SamplingParams) SamplingDecision
+}
+
+// SamplingParams describes the record a LogSampler decides on.
+type SamplingParams struct {
+	// Action is the name of the action.
+	Action string
+	// Message is the message of the record: "action started" for the decision of a span
+	// or "action ended" for the end record of a failed or slow span.
+	Message string
+	// Level is the level of the record.
+	Level slog.Level
+	// Time is the time of the record according to the configured clock.
+	Time time.Time
+	// Failed reports whether the action ended with an error.
+	Failed bool
+	// Slow reports whether the action ended successfully but slower than the slow threshold.
+	Slow bool
+}
+
+// SamplingDecision is the result of LogSampler.Sample.
+type SamplingDecision struct {
+	// Sample reports whether the record is logged.
+	Sample bool
+	// Rule names the rule that made the decision, e.g. "first" or "probability".
+	// If not empty, it is added to the logged record as the log_sampling attribute.
+	Rule string
+}
+
+// NewCountSampler returns a LogSampler that logs the first records of every action and message within each interval,
+// and then every thereafter-th record. A zero thereafter suppresses all records after the first ones until the interval ends.
+func NewCountSampler(interval time.Duration, first, thereafter int) LogSampler {
+	return &countSampler{
+		interval:   interval,
+		first:      int64(first),
+		thereafter: int64(thereafter),
+		windows:    xsync.NewMapOf[countSamplerKey, *countWindow](),
+	}
+}
+
+type countSamplerKey struct {
+	action  string
+	message string
+}
+
+type countWindow struct {
+	mu    sync.Mutex
+	start time.Time
+	n     int64
+}
+
+type countSampler struct {
+	interval   time.Duration
+	first      int64
+	thereafter int64
+	windows    *xsync.MapOf[countSamplerKey, *countWindow]
+}
+
+func (s *countSampler) Sample(_ context.Context, p SamplingParams) SamplingDecision {
+	w, _ := s.windows.LoadOrCompute(countSamplerKey{action: p.Action, message: p.Message}, func() *countWindow {
+		return &countWindow{start: p.Time}
+	})
+
+	w.mu.Lock()
+	if p.Time.Sub(w.start) >= s.interval {
+		w.start = p.Time
+		w.n = 0
+	}
+	w.n++
+	n := w.n
+	w.mu.Unlock()
+
+	if n <= s.first {
+		return SamplingDecision{Sample: true, Rule: "first"}
+	}
+
+	if s.thereafter > 0 && (n-s.first)%s.thereafter == 0 {
+		return SamplingDecision{Sample: true, Rule: "thereafter"}
+	}
+
+	return SamplingDecision{}
+}
+
+// NewProbabilitySampler returns a LogSampler that logs records with the given probability between 0 and 1.
+func NewProbabilitySampler(probability float64) LogSampler {
+	return probabilitySampler(probability)
+}
+
+type probabilitySampler float64
+
+func (s probabilitySampler) Sample(context.Context, SamplingParams) SamplingDecision {
+	if float64(s) >= 1 || rand.Float64() < float64(s) { //nolint:gosec // Log sampling doesn't need a secure random number.
+		return SamplingDecision{Sample: true, Rule: "probability"}
+	}
+
+	return SamplingDecision{}
+}
+
+// NewKeepFailuresSampler returns a LogSampler that always logs the records of failed and slow calls
+// and delegates the decision on other records to next.
+func NewKeepFailuresSampler(next LogSampler) LogSampler {
+	return keepFailuresSampler{next: next}
+}
+
+type keepFailuresSampler struct {
+	next LogSampler
+}
+
+func (s keepFailuresSampler) Sample(ctx context.Context, p SamplingParams) SamplingDecision {
+	switch {
+	case p.Failed:
+		return SamplingDecision{Sample: true, Rule: "failure"}
+	case p.Slow:
+		return SamplingDecision{Sample: true, Rule: "slow"}
+	default:
+		return s.next.Sample(ctx, p)
+	}
+}
+
+// suppressedLogs counts the records of an action suppressed since the last report.
+type suppressedLogs struct {
+	mu         sync.Mutex
+	count      int64
+	lastReport time.Time
+}
+
+// sample returns the decision of the configured LogSampler, or nil if no sampler is configured.
+func (cfg *SpanConfig) sample(ctx context.Context, p SamplingParams) *SamplingDecision {
+	if cfg.logSampler == nil {
+		return nil
+	}
+
+	decision := cfg.logSampler.Sample(ctx, p)
+
+	return &decision
+}
+
+// logSampled logs the record unless the trace sampling decision or the sampling decision of the span suppresses it.
+// Suppressed records are counted per action and reported in a "log records suppressed" record
+// at most once per report interval. The report is only logged along with a later record of the action,
+// so the records suppressed last before an action stops being called are reported by FlushSuppressedLogs.
+func (t *Tracer) logSampled(ctx context.Context, cfg *SpanConfig, p SamplingParams, sampling *SamplingDecision, pc uintptr, attrs ...slog.Attr) {
+	if !p.Failed && cfg.tracingEnabled && cfg.traceLogMode != TraceLogModeOff {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && !sc.IsSampled() {
+			if cfg.traceLogMode == TraceLogModeSuppress {
+				return
+			}
+			p.Level = cfg.logLevelOnUnsampled
+		}
+	}
+
+	if sampling == nil {
+		t.log(ctx, cfg, p.Message, p.Level, p.Time, pc, attrs...)
+		return
+	}
+
+	decision := *sampling
+	if !decision.Sample && (p.Failed || p.Slow) {
+		decision = cfg.logSampler.Sample(ctx, p)
+	}
+
+	t.reportSuppressedLogs(ctx, cfg, p, pc, !decision.Sample)
+
+	if !decision.Sample {
+		return
+	}
+
+	if decision.Rule != "" {
+		attrs = append(attrs, slog.String(logSamplingAttrKey, decision.Rule))
+	}
+
+	t.log(ctx, cfg, p.Message, p.Level, p.Time, pc, attrs...)
+}
+
+func (t *Tracer) reportSuppressedLogs(ctx context.Context, cfg *SpanConfig, p SamplingParams, pc uintptr, suppressed bool) {
+	s, _ := t.suppressedLogs.LoadOrCompute(p.Action, func() *suppressedLogs {
+		return &suppressedLogs{lastReport: p.Time}
+	})
+
+	s.mu.Lock()
+	if suppressed {
+		s.count++
+	}
+
+	var count int64
+	if s.count > 0 && p.Time.Sub(s.lastReport) >= cfg.logSuppressionReportInterval {
+		count = s.count
+		s.count = 0
+		s.lastReport = p.Time
+	}
+	s.mu.Unlock()
+
+	if count > 0 {
+		t.logSuppressed(ctx, cfg, p.Action, count, p.Time, pc)
+	}
+}
+
+// FlushSuppressedLogs reports the records suppressed since the last report of every action right away,
+// regardless of the report interval. Call it at shutdown, so that the last counts are not lost.
+func (t *Tracer) FlushSuppressedLogs(ctx context.Context) {
+	t.flushSuppressedLogs(ctx, callerPC(1))
+}
+
+func (t *Tracer) flushSuppressedLogs(ctx context.Context, pc uintptr) {
+	cfg := t.cfg.Load()
+	now := cfg.clock.Now()
+
+	t.suppressedLogs.Range(func(action string, s *suppressedLogs) bool {
+		s.mu.Lock()
+		count := s.count
+		s.count = 0
+		s.lastReport = now
+		s.mu.Unlock()
+
+		if count > 0 {
+			t.logSuppressed(ctx, cfg, action, count, now, pc)
+		}
+
+		return true
+	})
+}
+
+func (t *Tracer) logSuppressed(ctx context.Context, cfg *SpanConfig, action string, count int64, now time.Time, pc uintptr) {
+	t.log(ctx, cfg, "log records suppressed", cfg.logLevelOnSuccess, now, pc,
+		slog.String("action", action), slog.Int64("suppressed", count))
+}
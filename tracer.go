@@ -0,0 +1,194 @@
+package ft
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
+)
+
+// Tracer traces, logs and meters actions with its own configuration and metric instrument caches,
+// so that several independently configured instances can live in the same process.
+// The package-level functions use the default Tracer returned by Default.
+type Tracer struct {
+	cfg                 *atomic.Pointer[SpanConfig]
+	int64Counters       *xsync.MapOf[instrumentKey, metric.Int64Counter]
+	int64UpDownCounters *xsync.MapOf[instrumentKey, metric.Int64UpDownCounter]
+	durationHistograms  *xsync.MapOf[instrumentKey, metric.Float64Histogram]
+	metricAttrValues    *xsync.MapOf[string, *attrValueSet]
+	suppressedLogs      *xsync.MapOf[string, *suppressedLogs]
+}
+
+// New creates a Tracer configured with the given options.
+// Options passed to New become defaults for every span started by the Tracer.
+func New(opts ...Option) *Tracer {
+	cfg := defaultSpanConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Tracer{
+		cfg:                 atomic.NewPointer(&cfg),
+		int64Counters:       xsync.NewMapOf[instrumentKey, metric.Int64Counter](),
+		int64UpDownCounters: xsync.NewMapOf[instrumentKey, metric.Int64UpDownCounter](),
+		durationHistograms:  xsync.NewMapOf[instrumentKey, metric.Float64Histogram](),
+		metricAttrValues:    xsync.NewMapOf[string, *attrValueSet](),
+		suppressedLogs:      xsync.NewMapOf[string, *suppressedLogs](),
+	}
+}
+
+// Start begins a new traced and logged span for the given action.
+// It returns an updated context and a Span that should be ended when the operation completes.
+func (t *Tracer) Start(ctx context.Context, action string, opts ...Option) (context.Context, Span) {
+	return t.start(ctx, action, callerPC(1), opts)
+}
+
+// Now returns the current time according to the clock of the Tracer, e.g. to pass it to WithStartTime.
+func (t *Tracer) Now() time.Time {
+	return t.cfg.Load().clock.Now()
+}
+
+// configure applies the options on top of the current defaults of the Tracer.
+func (t *Tracer) configure(opts ...Option) {
+	for {
+		old := t.cfg.Load()
+		cfg := old.clone()
+		for _, opt := range opts {
+			opt(&cfg)
+		}
+
+		if t.cfg.CompareAndSwap(old, &cfg) {
+			return
+		}
+	}
+}
+
+func (t *Tracer) start(ctx context.Context, action string, pc uintptr, opts []Option) (context.Context, Span) {
+	cfg := t.cfg.Load().clone()
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	now := cfg.startTime
+	if now.IsZero() {
+		now = cfg.clock.Now()
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent, _ := ctx.Value(spanContextKey{}).(*span)
+	actionPathAttrs := cfg.actionPathAttrs(parent, action)
+	contextAttrs := cfg.contextAttrs(ctx)
+	spanAttrs := mergeAttrs(contextAttrs, cfg.additionalAttrs)
+
+	var otelSpan trace.Span
+
+	if cfg.tracingEnabled {
+		ctx, otelSpan = cfg.getTracerProvider().Tracer(
+			instrumentationName,
+			trace.WithSchemaURL(semconv.SchemaURL),
+		).Start(
+			ctx,
+			action,
+			trace.WithSpanKind(cfg.spanKind),
+			trace.WithAttributes(
+				attribute.String("action", action),
+			),
+			trace.WithTimestamp(now),
+		)
+	}
+
+	if otelSpan != nil && otelSpan.IsRecording() && cfg.appendOtelAttrs && len(spanAttrs) > 0 {
+		otelAttrs := make([]attribute.KeyValue, 0, len(spanAttrs))
+		for _, attr := range spanAttrs {
+			otelAttrs = append(otelAttrs, mapSlogAttrToOtel(attr))
+		}
+		otelSpan.SetAttributes(otelAttrs...)
+	}
+
+	var (
+		activeCounter metric.Int64UpDownCounter
+		activeAttrs   metric.MeasurementOption
+	)
+
+	if cfg.metricsEnabled {
+		mp := cfg.getMeterProvider()
+		names := cfg.metricNames(action)
+		metricAttrs := metric.WithAttributeSet(attribute.NewSet(t.metricAttrs(&cfg, action, spanAttrs)...))
+
+		if counter, ok := t.int64Counter(mp, names.calls); ok {
+			counter.Add(ctx, 1, metricAttrs)
+		}
+
+		if counter, ok := t.int64UpDownCounter(mp, names.active); ok {
+			counter.Add(ctx, 1, metricAttrs)
+			activeCounter = counter
+			activeAttrs = metricAttrs
+		}
+	}
+
+	startParams := SamplingParams{
+		Action:  action,
+		Message: "action started",
+		Level:   cfg.startLogLevel(),
+		Time:    now,
+	}
+	sampling := cfg.sample(ctx, startParams)
+
+	if cfg.startLogEnabled {
+		attrs := make([]slog.Attr, 0, 1+len(actionPathAttrs)+len(spanAttrs))
+		attrs = append(attrs, slog.String("action", action))
+		attrs = append(attrs, actionPathAttrs...)
+		attrs = append(attrs, spanAttrs...)
+
+		t.logSampled(ctx, &cfg, startParams, sampling, pc, attrs...)
+	}
+
+	s := &span{
+		tracer:          t,
+		parent:          parent,
+		cfg:             cfg,
+		start:           now,
+		action:          action,
+		pc:              pc,
+		sampling:        sampling,
+		traceSpan:       otelSpan,
+		activeCounter:   activeCounter,
+		activeAttrs:     activeAttrs,
+		contextAttrs:    contextAttrs,
+		actionPathAttrs: actionPathAttrs,
+		additionalAttrs: cfg.additionalAttrs,
+	}
+	s.ctx = context.WithValue(ctx, spanContextKey{}, s)
+
+	return s.ctx, s
+}
+
+func (t *Tracer) log(ctx context.Context, cfg *SpanConfig, msg string, level slog.Level, now time.Time, pc uintptr, attrs ...slog.Attr) {
+	if cfg.logOutput != LogOutputSlog {
+		emitOtelLog(ctx, cfg, msg, level, now, attrs)
+	}
+
+	if cfg.logOutput == LogOutputOtel {
+		return
+	}
+
+	handler := cfg.logger.Handler()
+
+	r := slog.NewRecord(now, level, msg, pc)
+	r.AddAttrs(attrs...)
+
+	if _, ok := handler.(*logHandler); !ok && cfg.tracingEnabled {
+		r.AddAttrs(cfg.traceLogFields.attrs(trace.SpanContextFromContext(ctx))...)
+	}
+
+	_ = handler.Handle(ctx, r)
+}